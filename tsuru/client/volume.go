@@ -5,34 +5,184 @@
 package client
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/ajg/form"
+	"github.com/ghodss/yaml"
+	"github.com/iancoleman/orderedmap"
 	"github.com/tsuru/gnuflag"
 	"github.com/tsuru/tablecli"
+	"github.com/tsuru/tsuru-client/tsuru/config"
 	"github.com/tsuru/tsuru-client/tsuru/formatter"
 	"github.com/tsuru/tsuru/cmd"
+	"github.com/tsuru/tsuru/event"
+	tsuruNet "github.com/tsuru/tsuru/net"
 	volumeTypes "github.com/tsuru/tsuru/types/volume"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
+const volumeQuietDesc = "suppress success messages, errors are still reported"
+
+// Exit codes of the volume commands when the API rejects a request, allowing
+// scripts to tell the most common failures apart. Any other failure exits
+// with status 1.
+const (
+	VolumeExitNotFound  = 3
+	VolumeExitForbidden = 4
+	VolumeExitConflict  = 5
+)
+
+var volumeExitCodes = map[int]int{
+	http.StatusNotFound:  VolumeExitNotFound,
+	http.StatusForbidden: VolumeExitForbidden,
+	http.StatusConflict:  VolumeExitConflict,
+}
+
+// volumeExitError is returned by volume commands failing with an API error
+// that has one of the exit codes above, which is reported by ExitCode.
+type volumeExitError struct {
+	error
+	code int
+}
+
+func (e *volumeExitError) Unwrap() error {
+	return e.error
+}
+
+func (e *volumeExitError) ExitCode() int {
+	return e.code
+}
+
+// volumeErrorExitCode returns the exit code matching the status code of the
+// API error err, or zero when the generic exit code should be used.
+func volumeErrorExitCode(err error) int {
+	var statusErr interface{ StatusCode() int }
+	if err != nil && errors.As(err, &statusErr) {
+		return volumeExitCodes[statusErr.StatusCode()]
+	}
+	return 0
+}
+
+// recordVolumeExitCode wraps the API error in *err, if any, in a
+// volumeExitError when it has a dedicated exit code.
+func recordVolumeExitCode(err *error) {
+	if code := volumeErrorExitCode(*err); code != 0 {
+		*err = &volumeExitError{error: *err, code: code}
+	}
+}
+
+// recordVolumeError wraps *err like recordVolumeExitCode and, when any of
+// jsonFlags is set, writes the error to stderr as a JSON object like
+// {"error": "..."}. The message is then replaced with cmd.ErrAbortCommand,
+// so it isn't printed again as plain text and the output stays parseable.
+func recordVolumeError(ctx *cmd.Context, err *error, jsonFlags ...*bool) {
+	if *err == nil || *err == cmd.ErrAbortCommand {
+		return
+	}
+	code := volumeErrorExitCode(*err)
+	for _, f := range jsonFlags {
+		if !*f {
+			continue
+		}
+		encoder := json.NewEncoder(ctx.Stderr)
+		encoder.SetEscapeHTML(false)
+		if encoder.Encode(map[string]string{"error": (*err).Error()}) == nil {
+			*err = cmd.ErrAbortCommand
+		}
+		break
+	}
+	if code != 0 {
+		*err = &volumeExitError{error: *err, code: code}
+	}
+}
+
 type VolumeCreate struct {
-	fs   *gnuflag.FlagSet
-	pool string
-	team string
-	opt  cmd.MapFlag
+	fs           *gnuflag.FlagSet
+	pool         string
+	team         string
+	opt          volumeOptFlag
+	optsFile     string
+	tags         cmd.MapFlag
+	strictOpts   bool
+	storageClass string
+	namespace    string
+	show         bool
+	json         bool
+	validatePlan bool
+	planCache    volumePlanCache
+	skipName     bool
+	wait         bool
+	waitTimeout  time.Duration
+	quiet        bool
+	bindApp      string
+	bindMount    string
+	keepOnFail   bool
+	noRestart    bool
+	conn         volumeConn
 }
 
 func (c *VolumeCreate) Info() *cmd.Info {
 	return &cmd.Info{
-		Name:    "volume-create",
-		Usage:   "volume create <volume-name> <plan-name> [-p/--pool <pool>] [-t/--team <team>] [-o/--opt key=value]...",
-		Desc:    `Creates a new persistent volume based on a volume plan.`,
+		Name:  "volume-create",
+		Usage: "volume create <volume-name> <plan-name> [-p/--pool <pool>] [-t/--team <team>] [-o/--opt key=value]... [--opts-file <file>] [--storage-class <class>] [--namespace <namespace>] [--tag key=value]... [--strict-opts] [--skip-name-validation] [--bind-app <app> --bind-mountpoint <mount point> [--keep-on-bind-failure] [--no-restart]] [--show] [--json]",
+		Desc: `Creates a new persistent volume based on a volume plan.
+
+Volume names must have at most 40 characters, containing only lower case
+letters, numbers or dashes, starting with a letter. Names not following these
+rules are rejected before reaching the server, unless --skip-name-validation
+is used.
+
+When the same option key is given more than once, the last value is used and
+a warning is displayed. With --strict-opts, the command fails instead.
+
+For kubernetes volumes, --storage-class and --namespace are shortcuts for
+-o storage-class=<class> and -o namespace=<namespace>. The option keys they
+set can be changed with the volume.storageClassOpt and volume.namespaceOpt
+settings in ~/.tsuru/config.json. They are applied after the -o flags, so
+giving both for the same key is reported as a duplicated option.
+
+Tags given with --tag are stored as options prefixed with "tsuru-tag-", so
+--tag team=payments is the same as -o tsuru-tag-team=payments.
+
+Options can also be read from a file with --opts-file. Files ending in .json,
+.yaml or .yml must contain a map of options, any other file is read as one
+key=value pair per line, ignoring blank lines and lines starting with #.
+Options given in the command line take precedence over the ones in the file.
+
+With --bind-app and --bind-mountpoint, the new volume is bound to the given
+application right after being created, displaying the progress of the bind.
+When the bind fails, the volume is removed, unless --keep-on-bind-failure is
+used. The application is restarted by the bind unless --no-restart is given,
+which defaults to the volume.noRestart setting in ~/.tsuru/config.json.
+
+The volume plans checked by --validate-plan are cached for a minute, sharing
+the cache with shell completion. Use --refresh-cache to fetch them again, or
+--no-cache to bypass the cache.
+
+When the pool or team flags are omitted, the values of the TSURU_POOL and
+TSURU_TEAM environment variables are used. Explicit flags always take
+precedence over the environment.`,
 		MinArgs: 2,
 		MaxArgs: 2,
 	}
@@ -41,62 +191,362 @@ func (c *VolumeCreate) Info() *cmd.Info {
 func (c *VolumeCreate) Flags() *gnuflag.FlagSet {
 	if c.fs == nil {
 		c.fs = gnuflag.NewFlagSet("volume-create", gnuflag.ExitOnError)
-		desc := "the pool that owns the service (mandatory if the user has access to more than one pool)"
+		desc := "the pool that owns the service (mandatory if the user has access to more than one pool, defaults to $TSURU_POOL)"
 		c.fs.StringVar(&c.pool, "pool", "", desc)
 		c.fs.StringVar(&c.pool, "p", "", desc)
-		desc = "the team that owns the service (mandatory if the user has access to more than one team)"
+		desc = "the team that owns the service (mandatory if the user has access to more than one team, defaults to $TSURU_TEAM)"
 		c.fs.StringVar(&c.team, "team", "", desc)
 		c.fs.StringVar(&c.team, "t", "", desc)
 		desc = "backend specific volume options"
 		c.fs.Var(&c.opt, "opt", desc)
 		c.fs.Var(&c.opt, "o", desc)
+		c.fs.StringVar(&c.optsFile, "opts-file", "", "read backend specific volume options from a key=value, JSON or YAML file")
+		c.fs.BoolVar(&c.strictOpts, "strict-opts", false, volumeStrictOptsDesc)
+		c.fs.StringVar(&c.storageClass, "storage-class", "", "kubernetes storage class of the volume, same as -o storage-class=<class>")
+		c.fs.Var(&c.tags, "tag", volumeTagFlagDesc)
+		c.fs.StringVar(&c.namespace, "namespace", "", "kubernetes namespace of the volume, same as -o namespace=<namespace>")
+		c.fs.BoolVar(&c.show, "show", false, "display the created volume")
+		c.fs.BoolVar(&c.json, "json", false, "display the created volume in JSON format")
+		c.fs.BoolVar(&c.validatePlan, "validate-plan", false, "check that the plan exists before creating the volume")
+		c.planCache.addFlags(c.fs)
+		c.fs.BoolVar(&c.skipName, "skip-name-validation", false, "don't check the volume name before sending it to the server")
+		c.fs.BoolVar(&c.wait, "wait", false, "wait until the volume is provisioned")
+		c.fs.DurationVar(&c.waitTimeout, "wait-timeout", 5*time.Minute, "with --wait, give up after this duration (e.g. 10m)")
+		c.fs.StringVar(&c.bindApp, "bind-app", "", "bind the volume to this app after creating it")
+		c.fs.StringVar(&c.bindMount, "bind-mountpoint", "", "with --bind-app, the mount point of the bind")
+		c.fs.BoolVar(&c.keepOnFail, "keep-on-bind-failure", false, "with --bind-app, keep the volume when the bind fails instead of removing it")
+		c.fs.BoolVar(&c.noRestart, "no-restart", false, "with --bind-app, don't restart the app after binding the volume")
+		c.fs.BoolVar(&c.quiet, "quiet", false, volumeQuietDesc)
+		c.fs.BoolVar(&c.quiet, "q", false, volumeQuietDesc)
+		c.conn.addFlags(c.fs)
+		c.conn.addBodyFlag(c.fs)
 	}
 	return c.fs
 }
 
-func (c *VolumeCreate) Run(ctx *cmd.Context, client *cmd.Client) error {
+func (c *VolumeCreate) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeError(ctx, &err, &c.json)
+	if !c.skipName {
+		if err := checkVolumeName(ctx.Args[0]); err != nil {
+			return err
+		}
+	}
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	if (c.bindApp == "") != (c.bindMount == "") {
+		return errors.New("the --bind-app and --bind-mountpoint flags must be given together")
+	}
+	if c.keepOnFail && c.bindApp == "" {
+		return errors.New("the --keep-on-bind-failure flag requires --bind-app")
+	}
+	if c.noRestart && c.bindApp == "" {
+		return errors.New("the --no-restart flag requires --bind-app")
+	}
+	if c.bindMount != "" {
+		c.bindMount = normalizeMountPoint(ctx.Stderr, c.bindMount)
+		if err := checkMountPoint(c.bindMount); err != nil {
+			return err
+		}
+	}
+	if err := c.setKubernetesOpts(); err != nil {
+		return err
+	}
+	if err := setVolumeTags(&c.opt, c.tags); err != nil {
+		return err
+	}
+	if err := c.opt.check(ctx.Stderr, c.strictOpts); err != nil {
+		return err
+	}
+	if c.optsFile != "" {
+		fileOpts, err := readVolumeOptsFile(c.optsFile)
+		if err != nil {
+			return err
+		}
+		if c.opt.MapFlag == nil {
+			c.opt.MapFlag = cmd.MapFlag{}
+		}
+		for k, v := range fileOpts {
+			if _, ok := c.opt.MapFlag[k]; !ok {
+				c.opt.MapFlag[k] = v
+			}
+		}
+	}
 	volumeName, planName := ctx.Args[0], ctx.Args[1]
+	if c.validatePlan {
+		if err := c.conn.checkVolumePlan(client, &c.planCache, planName); err != nil {
+			return err
+		}
+	}
 	vol := volumeTypes.Volume{
 		Name:      volumeName,
 		Plan:      volumeTypes.VolumePlan{Name: planName},
-		Pool:      c.pool,
-		TeamOwner: c.team,
-		Opts:      map[string]string(c.opt),
+		Pool:      flagOrEnv(c.pool, volumePoolEnv),
+		TeamOwner: flagOrEnv(c.team, volumeTeamEnv),
+		Opts:      map[string]string(c.opt.MapFlag),
 	}
-	val, err := form.EncodeToValues(vol)
+	err = c.conn.createVolume(client, vol)
 	if err != nil {
 		return err
 	}
-	body := strings.NewReader(val.Encode())
-	u, err := cmd.GetURLVersion("1.4", "/volumes")
+	if !c.json && !c.quiet {
+		fmt.Fprint(ctx.Stdout, "Volume successfully created.\n")
+	}
+	if c.wait {
+		if err = c.conn.waitVolumeProvisioned(ctx, client, volumeName, c.waitTimeout); err != nil {
+			return err
+		}
+	}
+	if c.bindApp != "" {
+		if err = c.bind(ctx, client, volumeName); err != nil {
+			return err
+		}
+	}
+	if !c.show && !c.json {
+		return nil
+	}
+	volume, err := c.conn.getVolume(client, volumeName)
 	if err != nil {
 		return err
 	}
-	request, err := http.NewRequest("POST", u, body)
+	if volume == nil {
+		return fmt.Errorf("volume %q not found after creation", volumeName)
+	}
+	if c.json {
+		return formatter.JSON(ctx.Stdout, volume)
+	}
+	fmt.Fprintln(ctx.Stdout)
+	return (&VolumeInfo{}).render(ctx, *volume)
+}
+
+// bind binds the volume just created to the app given in --bind-app,
+// removing the volume when it fails unless --keep-on-bind-failure is set.
+func (c *VolumeCreate) bind(ctx *cmd.Context, client *cmd.Client, volumeName string) error {
+	if !c.json && !c.quiet {
+		fmt.Fprintf(ctx.Stdout, "Binding volume %q to app %q at %q...\n", volumeName, c.bindApp, c.bindMount)
+	}
+	configNoRestart(ctx.Stderr, c.fs, &c.noRestart)
+	// The progress events are not displayed in JSON mode, keeping stdout
+	// parseable.
+	bind := &VolumeBind{conn: c.conn, plain: c.json || c.quiet, noRestart: c.noRestart}
+	err := bind.bind(ctx, client, volumeName, c.bindApp, c.bindMount)
+	if err == nil {
+		if !c.json && !c.quiet {
+			fmt.Fprint(ctx.Stdout, "Volume successfully bound.\n")
+		}
+		return nil
+	}
+	if c.keepOnFail {
+		return fmt.Errorf("volume %q was created, but binding it to app %q failed: %w", volumeName, c.bindApp, err)
+	}
+	fmt.Fprintf(ctx.Stderr, "Failed to bind volume %q, removing it...\n", volumeName)
+	if rmErr := c.conn.removeVolume(client, volumeName); rmErr != nil {
+		fmt.Fprintf(ctx.Stderr, "Failed to remove volume %q: %v\n", volumeName, rmErr)
+	}
+	return err
+}
+
+const (
+	volumePoolEnv = "TSURU_POOL"
+	volumeTeamEnv = "TSURU_TEAM"
+)
+
+// flagOrEnv returns value when it's set, falling back to the environment
+// variable envVar otherwise.
+func flagOrEnv(value, envVar string) string {
+	if value != "" {
+		return value
+	}
+	return os.Getenv(envVar)
+}
+
+// volumeClientConfig returns the volume settings of the client config.
+var volumeClientConfig = func() config.VolumeConfig {
+	conf := config.GetConfig()
+	if conf == nil || conf.Volume == nil {
+		return config.VolumeConfig{}
+	}
+	return *conf.Volume
+}
+
+// configNoRestart sets noRestart when the volume.noRestart setting is
+// enabled in the client config, unless --no-restart was given in the
+// command line, printing a notice to w so the default isn't surprising.
+func configNoRestart(w io.Writer, fs *gnuflag.FlagSet, noRestart *bool) {
+	if *noRestart || !volumeClientConfig().NoRestart {
+		return
+	}
+	explicit := false
+	if fs != nil {
+		fs.Visit(func(f *gnuflag.Flag) {
+			if f.Name == "no-restart" {
+				explicit = true
+			}
+		})
+	}
+	if explicit {
+		return
+	}
+	*noRestart = true
+	fmt.Fprintln(w, "Note: the application won't be restarted, as set by volume.noRestart in the client config. Use --no-restart=false to restart it.")
+}
+
+// configOutput returns the default output format set in the client config,
+// unless one of formatFlags was given in the command line, which always
+// takes precedence. valid lists the formats supported by the command.
+func configOutput(fs *gnuflag.FlagSet, formatFlags []string, valid ...string) (string, error) {
+	output := volumeClientConfig().Output
+	if output == "" {
+		return "", nil
+	}
+	explicit := false
+	if fs != nil {
+		fs.Visit(func(f *gnuflag.Flag) {
+			if containsString(formatFlags, f.Name) {
+				explicit = true
+			}
+		})
+	}
+	if explicit {
+		return "", nil
+	}
+	if !containsString(valid, output) {
+		return "", fmt.Errorf("invalid volume.output %q in the client config, valid options are: %s", output, strings.Join(valid, ", "))
+	}
+	return output, nil
+}
+
+var (
+	volumeWaitInterval = 2 * time.Second
+
+	volumeReadyStatuses   = []string{"ready", "provisioned", "bound", "available"}
+	volumeFailedStatuses  = []string{"failed", "error"}
+	volumeWaitConfirmRuns = 2
+)
+
+// waitVolumeProvisioned polls the volume until it reports a ready status,
+// printing progress dots to stderr. As the API may not report any status,
+// a volume with an empty status is considered ready once it's returned by
+// the API in volumeWaitConfirmRuns consecutive polls.
+func (t *volumeConn) waitVolumeProvisioned(ctx *cmd.Context, client *cmd.Client, volumeName string, timeout time.Duration) error {
+	fmt.Fprintf(ctx.Stderr, "Waiting for volume %q to be provisioned", volumeName)
+	defer fmt.Fprintln(ctx.Stderr)
+	deadline := time.Now().Add(timeout)
+	var confirmed int
+	var lastErr error
+	for {
+		volume, err := t.getVolume(client, volumeName)
+		switch {
+		case err != nil:
+			confirmed, lastErr = 0, err
+		case volume == nil:
+			confirmed, lastErr = 0, fmt.Errorf("volume %q not found", volumeName)
+		default:
+			status := strings.ToLower(volume.Status)
+			if containsString(volumeReadyStatuses, status) {
+				return nil
+			}
+			if containsString(volumeFailedStatuses, status) {
+				return fmt.Errorf("volume %q failed to be provisioned: status is %q", volumeName, volume.Status)
+			}
+			if status == "" {
+				confirmed++
+			}
+			if confirmed >= volumeWaitConfirmRuns {
+				return nil
+			}
+			lastErr = nil
+		}
+		if timeout > 0 && time.Now().Add(volumeWaitInterval).After(deadline) {
+			if lastErr != nil {
+				return fmt.Errorf("timed out waiting for volume %q to be provisioned after %s: %w", volumeName, timeout, lastErr)
+			}
+			return fmt.Errorf("timed out waiting for volume %q to be provisioned after %s", volumeName, timeout)
+		}
+		fmt.Fprint(ctx.Stderr, ".")
+		time.Sleep(volumeWaitInterval)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *volumeConn) checkVolumePlan(client *cmd.Client, cache *volumePlanCache, planName string) error {
+	plans, err := cache.plans(t, client)
 	if err != nil {
 		return err
 	}
-	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	_, err = client.Do(request)
+	var names []string
+	for _, provPlans := range plans {
+		for _, p := range provPlans {
+			if p.Name == planName {
+				return nil
+			}
+			names = append(names, p.Name)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("plan %q not found, no volume plans are available", planName)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("plan %q not found, available plans: %s", planName, strings.Join(names, ", "))
+}
+
+func (t *volumeConn) createVolume(client *cmd.Client, vol volumeTypes.Volume) error {
+	body, contentType, err := encodeVolumeBody(vol, t.jsonBody)
 	if err != nil {
 		return err
 	}
-	fmt.Fprint(ctx.Stdout, "Volume successfully created.\n")
-	return nil
+	u, err := t.volumeURL("/volumes")
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest("POST", u, body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", contentType)
+	_, err = client.Do(request)
+	return err
 }
 
 type VolumeUpdate struct {
-	fs   *gnuflag.FlagSet
-	pool string
-	team string
-	opt  cmd.MapFlag
+	cmd.ConfirmationCommand
+	fs         *gnuflag.FlagSet
+	pool       string
+	team       string
+	opt        volumeOptFlag
+	tags       cmd.MapFlag
+	strictOpts bool
+	quiet      bool
+	conn       volumeConn
 }
 
 func (c *VolumeUpdate) Info() *cmd.Info {
 	return &cmd.Info{
-		Name:    "volume-update",
-		Usage:   "volume update <volume name> <plan-name> [-p/--pool <pool>] [-t/--team <team>] [-o/--opt key=value]...",
-		Desc:    `Update an existing persistent volume.`,
+		Name:  "volume-update",
+		Usage: "volume update <volume name> <plan-name> [-p/--pool <pool>] [-t/--team <team>] [-o/--opt key=value]... [--tag key=value]... [--strict-opts] [-y/--assume-yes]",
+		Desc: `Update an existing persistent volume.
+
+Changing the plan of a volume may be disruptive or unsupported by the
+backend, so a warning is displayed and a confirmation is required when the
+given plan differs from the current one.
+
+Tags given with --tag are stored as options prefixed with "tsuru-tag-", so
+--tag team=payments is the same as -o tsuru-tag-team=payments.
+
+When the same option key is given more than once, the last value is used and
+a warning is displayed. With --strict-opts, the command fails instead.
+
+When the pool or team flags are omitted, the values of the TSURU_POOL and
+TSURU_TEAM environment variables are used. Explicit flags always take
+precedence over the environment.`,
 		MinArgs: 2,
 		MaxArgs: 2,
 	}
@@ -104,527 +554,4622 @@ func (c *VolumeUpdate) Info() *cmd.Info {
 
 func (c *VolumeUpdate) Flags() *gnuflag.FlagSet {
 	if c.fs == nil {
-		c.fs = gnuflag.NewFlagSet("volume-update", gnuflag.ExitOnError)
-		desc := "the pool that owns the service (mandatory if the user has access to more than one pool)"
-		c.fs.StringVar(&c.pool, "pool", "", desc)
-		c.fs.StringVar(&c.pool, "p", "", desc)
-		desc = "the team that owns the service (mandatory if the user has access to more than one team)"
-		c.fs.StringVar(&c.team, "team", "", desc)
-		c.fs.StringVar(&c.team, "t", "", desc)
+		fs := gnuflag.NewFlagSet("volume-update", gnuflag.ExitOnError)
+		desc := "the pool that owns the service (mandatory if the user has access to more than one pool, defaults to $TSURU_POOL)"
+		fs.StringVar(&c.pool, "pool", "", desc)
+		fs.StringVar(&c.pool, "p", "", desc)
+		desc = "the team that owns the service (mandatory if the user has access to more than one team, defaults to $TSURU_TEAM)"
+		fs.StringVar(&c.team, "team", "", desc)
+		fs.StringVar(&c.team, "t", "", desc)
 		desc = "backend specific volume options"
-		c.fs.Var(&c.opt, "opt", desc)
-		c.fs.Var(&c.opt, "o", desc)
+		fs.Var(&c.opt, "opt", desc)
+		fs.Var(&c.opt, "o", desc)
+		fs.Var(&c.tags, "tag", volumeTagFlagDesc)
+		fs.BoolVar(&c.strictOpts, "strict-opts", false, volumeStrictOptsDesc)
+		fs.BoolVar(&c.quiet, "quiet", false, volumeQuietDesc)
+		fs.BoolVar(&c.quiet, "q", false, volumeQuietDesc)
+		c.conn.addFlags(fs)
+		c.conn.addBodyFlag(fs)
+		c.fs = cmd.MergeFlagSet(c.ConfirmationCommand.Flags(), fs)
 	}
 	return c.fs
 }
 
-func (c *VolumeUpdate) Run(ctx *cmd.Context, client *cmd.Client) error {
+func (c *VolumeUpdate) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeExitCode(&err)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	if err := setVolumeTags(&c.opt, c.tags); err != nil {
+		return err
+	}
+	if err := c.opt.check(ctx.Stderr, c.strictOpts); err != nil {
+		return err
+	}
 	volumeName, planName := ctx.Args[0], ctx.Args[1]
+	if !assumeYes(c.Flags()) {
+		confirmed, err := c.confirmPlanChange(ctx, client, volumeName, planName)
+		if err != nil || !confirmed {
+			return err
+		}
+	}
 	vol := volumeTypes.Volume{
 		Name:      volumeName,
 		Plan:      volumeTypes.VolumePlan{Name: planName},
-		Pool:      c.pool,
-		TeamOwner: c.team,
-		Opts:      map[string]string(c.opt),
+		Pool:      flagOrEnv(c.pool, volumePoolEnv),
+		TeamOwner: flagOrEnv(c.team, volumeTeamEnv),
+		Opts:      map[string]string(c.opt.MapFlag),
 	}
-	val, err := form.EncodeToValues(vol)
+	err = c.conn.updateVolume(client, vol)
 	if err != nil {
 		return err
 	}
-	body := strings.NewReader(val.Encode())
-	u, err := cmd.GetURLVersion("1.4", "/volumes/"+volumeName)
-	if err != nil {
-		return err
+	if !c.quiet {
+		fmt.Fprint(ctx.Stdout, "Volume successfully updated.\n")
 	}
-	request, err := http.NewRequest("POST", u, body)
+	return nil
+}
+
+// confirmPlanChange warns about changing the plan of the volume and asks for
+// confirmation, which is only needed when planName isn't its current plan.
+func (c *VolumeUpdate) confirmPlanChange(ctx *cmd.Context, client *cmd.Client, volumeName, planName string) (bool, error) {
+	current, err := c.conn.getVolume(client, volumeName)
 	if err != nil {
-		return err
+		return false, err
 	}
-	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	_, err = client.Do(request)
-	if err != nil {
-		return err
+	if current == nil || current.Plan.Name == planName {
+		return true, nil
 	}
-	fmt.Fprint(ctx.Stdout, "Volume successfully updated.\n")
-	return nil
+	warning := fmt.Sprintf("Warning: changing the plan of volume %q from %q to %q may be disruptive or unsupported by the backend.", volumeName, current.Plan.Name, planName)
+	if colorsEnabled(ctx.Stderr) {
+		warning = cmd.Colorfy(warning, "red", "", "bold")
+	}
+	fmt.Fprintln(ctx.Stderr, warning)
+	return c.Confirm(ctx, "Are you sure you want to change the plan?"), nil
 }
 
-type volumeFilter struct {
-	name      string
-	pool      string
-	plan      string
-	teamOwner string
+// assumeYes reports whether -y/--assume-yes was given in the parsed flags
+// fs of a command embedding cmd.ConfirmationCommand, allowing it to skip the
+// requests only needed to build its questions.
+func assumeYes(fs *gnuflag.FlagSet) bool {
+	f := fs.Lookup("assume-yes")
+	return f != nil && f.Value.String() == "true"
 }
 
-func (f *volumeFilter) queryString() (url.Values, error) {
-	result := make(url.Values)
-	if f.name != "" {
-		result.Set("name", f.name)
+// readVolumeOptsFile reads volume options from the file at path. JSON and
+// YAML files must contain a map, other files are parsed as key=value lines,
+// where blank lines and lines starting with # are ignored.
+func readVolumeOptsFile(path string) (map[string]string, error) {
+	f, err := filesystem().Open(path)
+	if err != nil {
+		return nil, err
 	}
-	if f.teamOwner != "" {
-		result.Set("teamOwner", f.teamOwner)
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
 	}
-	if f.pool != "" {
-		result.Set("pool", f.pool)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		var raw map[string]interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("unable to parse options file %q: %w", path, err)
+		}
+		opts := make(map[string]string, len(raw))
+		for k, v := range raw {
+			switch v := v.(type) {
+			case map[string]interface{}, []interface{}:
+				return nil, fmt.Errorf("invalid value for option %q in %q: nested values are not supported", k, path)
+			case nil:
+				opts[k] = ""
+			case float64:
+				opts[k] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				opts[k] = fmt.Sprint(v)
+			}
+		}
+		return opts, nil
 	}
-	if f.plan != "" {
-		result.Set("plan", f.plan)
+	opts := map[string]string{}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || key == "" {
+			return nil, fmt.Errorf("invalid line %d in options file %q: expected key=value", i+1, path)
+		}
+		opts[key] = strings.TrimSpace(parts[1])
 	}
-	return result, nil
+	return opts, nil
 }
 
-type VolumeList struct {
-	fs         *gnuflag.FlagSet
-	filter     volumeFilter
-	simplified bool
-	json       bool
+// volumeNameRegexp mirrors the volume name rule enforced by the tsuru API.
+var volumeNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9-]{0,39}$`)
+
+// checkVolumeName returns an error when name would be rejected by the tsuru
+// API as a volume name.
+func checkVolumeName(name string) error {
+	if volumeNameRegexp.MatchString(name) {
+		return nil
+	}
+	return fmt.Errorf("invalid volume name %q: it must have at most 40 characters, containing only lower case letters, numbers or dashes, starting with a letter (use --skip-name-validation to send it anyway)", name)
 }
 
-func (c *VolumeList) Info() *cmd.Info {
-	return &cmd.Info{
-		Name:    "volume-list",
-		Usage:   "volume list",
-		Desc:    `Lists existing persistent volumes.`,
-		MinArgs: 0,
-		MaxArgs: 0,
+const (
+	volumeStorageClassOpt = "storage-class"
+	volumeNamespaceOpt    = "namespace"
+)
+
+// setKubernetesOpts adds the options given with the --storage-class and
+// --namespace flags, using the keys set in the client config, if any.
+func (c *VolumeCreate) setKubernetesOpts() error {
+	conf := volumeClientConfig()
+	opts := []struct{ key, defaultKey, value string }{
+		{conf.StorageClassOpt, volumeStorageClassOpt, c.storageClass},
+		{conf.NamespaceOpt, volumeNamespaceOpt, c.namespace},
+	}
+	for _, o := range opts {
+		if o.value == "" {
+			continue
+		}
+		key := o.key
+		if key == "" {
+			key = o.defaultKey
+		}
+		if err := c.opt.Set(key + "=" + o.value); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (c *VolumeList) Flags() *gnuflag.FlagSet {
-	if c.fs == nil {
-		c.fs = gnuflag.NewFlagSet("volume-list", gnuflag.ExitOnError)
-		c.fs.StringVar(&c.filter.name, "name", "", "Filter volumes by name")
-		c.fs.StringVar(&c.filter.name, "n", "", "Filter volumes by name")
-		c.fs.StringVar(&c.filter.pool, "pool", "", "Filter volumes by pool")
-		c.fs.StringVar(&c.filter.pool, "o", "", "Filter volumes by pool")
-		c.fs.StringVar(&c.filter.plan, "plan", "", "Filter volumes by plan")
-		c.fs.StringVar(&c.filter.plan, "p", "", "Filter volumes by plan")
-		c.fs.StringVar(&c.filter.teamOwner, "team", "", "Filter volumes by team owner")
-		c.fs.StringVar(&c.filter.teamOwner, "t", "", "Filter volumes by team owner")
-		c.fs.BoolVar(&c.simplified, "q", false, "Display only volumes name")
-		c.fs.BoolVar(&c.json, "json", false, "Display in JSON format")
+// Tags are stored as volume options whose keys have the volumeTagPrefix, as
+// volumes have no dedicated field for them.
+const (
+	volumeTagPrefix   = "tsuru-tag-"
+	volumeTagFlagDesc = "tag the volume for cost allocation and filtering, may be given multiple times"
+)
 
+// setVolumeTags adds tags to opt as options with the volumeTagPrefix.
+func setVolumeTags(opt *volumeOptFlag, tags cmd.MapFlag) error {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
 	}
-	return c.fs
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == "" {
+			return errors.New("tag keys must not be empty")
+		}
+		if err := opt.Set(volumeTagPrefix + k + "=" + tags[k]); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (c *VolumeList) Run(ctx *cmd.Context, client *cmd.Client) error {
-	qs, err := c.filter.queryString()
-	if err != nil {
-		return err
+// splitVolumeTags separates the tags stored in opts from the other options.
+func splitVolumeTags(opts map[string]string) (map[string]string, map[string]string) {
+	tags := map[string]string{}
+	others := map[string]string{}
+	for k, v := range opts {
+		if strings.HasPrefix(k, volumeTagPrefix) {
+			tags[strings.TrimPrefix(k, volumeTagPrefix)] = v
+		} else {
+			others[k] = v
+		}
 	}
+	return tags, others
+}
 
-	u, err := cmd.GetURLVersion("1.4", fmt.Sprintf("/volumes?%s", qs.Encode()))
+func (t *volumeConn) updateVolume(client *cmd.Client, vol volumeTypes.Volume) error {
+	body, contentType, err := encodeVolumeBody(vol, t.jsonBody)
 	if err != nil {
 		return err
 	}
-	request, err := http.NewRequest("GET", u, nil)
+	u, err := t.volumeURL("/volumes/" + vol.Name)
 	if err != nil {
 		return err
 	}
-	rsp, err := client.Do(request)
+	request, err := http.NewRequest("POST", u, body)
 	if err != nil {
 		return err
 	}
-	defer rsp.Body.Close()
-	if rsp.StatusCode == http.StatusNoContent {
-		fmt.Fprintln(ctx.Stdout, "No volumes available.")
-		return nil
-	}
-	data, err := io.ReadAll(rsp.Body)
-	if err != nil {
+	request.Header.Set("Content-Type", contentType)
+	_, err = client.Do(request)
+	return err
+}
+
+const volumeStrictOptsDesc = "fail when the same option key is given more than once"
+
+// volumeOptFlag is a cmd.MapFlag that keeps track of the keys given more
+// than once, whose previous values would otherwise be silently overridden.
+type volumeOptFlag struct {
+	cmd.MapFlag
+	duplicates []volumeOptDuplicate
+}
+
+type volumeOptDuplicate struct {
+	key      string
+	previous string
+	value    string
+}
+
+func (f *volumeOptFlag) Set(val string) error {
+	parts := strings.SplitN(val, "=", 2)
+	previous, ok := f.MapFlag[parts[0]]
+	if err := f.MapFlag.Set(val); err != nil {
 		return err
 	}
-	var volumes []volumeTypes.Volume
-	err = json.Unmarshal(data, &volumes)
-	if err != nil {
-		return err
+	if ok {
+		f.duplicates = append(f.duplicates, volumeOptDuplicate{key: parts[0], previous: previous, value: parts[1]})
 	}
-	volumes = c.clientSideFilter(volumes)
-	return c.render(ctx, volumes)
+	return nil
 }
 
-func (c *VolumeList) clientSideFilter(volumes []volumeTypes.Volume) []volumeTypes.Volume {
-	result := make([]volumeTypes.Volume, 0, len(volumes))
-
-	for _, v := range volumes {
-		insert := true
-		if c.filter.name != "" && !strings.Contains(v.Name, c.filter.name) {
-			insert = false
+// check reports the keys given more than once, writing a warning to w for
+// each of them, or returning an error when strict is set.
+func (f *volumeOptFlag) check(w io.Writer, strict bool) error {
+	for _, d := range f.duplicates {
+		if strict {
+			return fmt.Errorf("option %q given more than once, with values %q and %q", d.key, d.previous, d.value)
 		}
+		fmt.Fprintf(w, "Warning: option %q given more than once, with values %q and %q. Using %q.\n", d.key, d.previous, d.value, d.value)
+	}
+	return nil
+}
+
+type volumeFilter struct {
+	name      string
+	pool      string
+	plan      string
+	teamOwner string
+	nameRegex string
+	app       string
+	bound     bool
+	unbound   bool
+	tags      cmd.MapFlag
+	opts      volumeOptMatchFlag
+
+	nameRE *regexp.Regexp
+	teams  []string
+}
+
+// volumeOptMatch is an option filter given as key=value, or only as key to
+// match any value.
+type volumeOptMatch struct {
+	key      string
+	value    string
+	anyValue bool
+}
 
-		if c.filter.pool != "" && v.Pool != c.filter.pool {
-			insert = false
+type volumeOptMatchFlag []volumeOptMatch
+
+func (f *volumeOptMatchFlag) String() string {
+	parts := make([]string, 0, len(*f))
+	for _, m := range *f {
+		if m.anyValue {
+			parts = append(parts, m.key)
+		} else {
+			parts = append(parts, m.key+"="+m.value)
 		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f *volumeOptMatchFlag) Set(val string) error {
+	parts := strings.SplitN(val, "=", 2)
+	if parts[0] == "" {
+		return fmt.Errorf("invalid option filter %q, it must be on the form \"key\" or \"key=value\"", val)
+	}
+	m := volumeOptMatch{key: parts[0], anyValue: len(parts) == 1}
+	if !m.anyValue {
+		m.value = parts[1]
+	}
+	*f = append(*f, m)
+	return nil
+}
 
-		if c.filter.plan != "" && v.Plan.Name != c.filter.plan {
-			insert = false
+// matches reports whether opts has every option in the filter.
+func (f volumeOptMatchFlag) matches(opts map[string]string) bool {
+	for _, m := range f {
+		value, ok := opts[m.key]
+		if !ok || (!m.anyValue && value != m.value) {
+			return false
 		}
+	}
+	return true
+}
+
+func (f *volumeFilter) addFlags(fs *gnuflag.FlagSet) {
+	fs.StringVar(&f.name, "name", "", "Filter volumes by name")
+	fs.StringVar(&f.name, "n", "", "Filter volumes by name")
+	fs.StringVar(&f.nameRegex, "name-regex", "", "Filter volumes by name using a regular expression")
+	fs.StringVar(&f.pool, "pool", "", "Filter volumes by pool (defaults to $TSURU_POOL)")
+	fs.StringVar(&f.pool, "o", "", "Filter volumes by pool (defaults to $TSURU_POOL)")
+	fs.StringVar(&f.plan, "plan", "", "Filter volumes by plan")
+	fs.StringVar(&f.plan, "p", "", "Filter volumes by plan")
+	fs.StringVar(&f.teamOwner, "team", "", "Filter volumes by team owner, given as a comma-separated list to match any of them (defaults to $TSURU_TEAM)")
+	fs.StringVar(&f.teamOwner, "t", "", "Filter volumes by team owner, given as a comma-separated list to match any of them (defaults to $TSURU_TEAM)")
+	fs.StringVar(&f.app, "app", "", "Filter volumes bound to the given app (exact match)")
+	fs.StringVar(&f.app, "a", "", "Filter volumes bound to the given app (exact match)")
+	fs.BoolVar(&f.bound, "bound", false, "Display only volumes bound to at least one app")
+	fs.BoolVar(&f.unbound, "unbound", false, "Display only volumes not bound to any app")
+	fs.Var(&f.tags, "tag", "Filter volumes by tag, in the form key=value, may be given multiple times")
+	fs.Var(&f.opts, "opt", "Filter volumes by option, in the form key=value or just key to match any value, may be given multiple times")
+}
 
-		if c.filter.teamOwner != "" && v.TeamOwner != c.filter.teamOwner {
-			insert = false
+func (f *volumeFilter) validate() error {
+	if f.bound && f.unbound {
+		return errors.New("the --bound and --unbound flags are mutually exclusive")
+	}
+	if f.nameRegex != "" {
+		re, err := regexp.Compile(f.nameRegex)
+		if err != nil {
+			return fmt.Errorf("invalid --name-regex pattern: %w", err)
+		}
+		f.nameRE = re
+	}
+	f.teams = nil
+	if f.teamOwner != "" {
+		for _, team := range strings.Split(f.teamOwner, ",") {
+			team = strings.TrimSpace(team)
+			if team == "" {
+				return fmt.Errorf("invalid team list %q: team names can't be empty", f.teamOwner)
+			}
+			f.teams = append(f.teams, team)
 		}
+	}
+	return nil
+}
+
+func (f *volumeFilter) isEmpty() bool {
+	return f.name == "" && f.nameRegex == "" && f.pool == "" && f.plan == "" &&
+		f.teamOwner == "" && f.app == "" && !f.bound && !f.unbound && len(f.tags) == 0 &&
+		len(f.opts) == 0
+}
 
-		if insert {
+func (f *volumeFilter) apply(volumes []volumeTypes.Volume) []volumeTypes.Volume {
+	result := make([]volumeTypes.Volume, 0, len(volumes))
+	for _, v := range volumes {
+		if f.matches(v) {
 			result = append(result, v)
 		}
 	}
-
 	return result
 }
 
-func (c *VolumeList) render(ctx *cmd.Context, volumes []volumeTypes.Volume) error {
-	if c.simplified {
-		for _, v := range volumes {
-			fmt.Fprintln(ctx.Stdout, v.Name)
+func (f *volumeFilter) matches(v volumeTypes.Volume) bool {
+	if !f.matchesQuery(v) {
+		return false
+	}
+	if f.nameRE != nil && !f.nameRE.MatchString(v.Name) {
+		return false
+	}
+	if f.app != "" && !volumeBoundToApp(v, f.app) {
+		return false
+	}
+	if f.bound && len(v.Binds) == 0 {
+		return false
+	}
+	if f.unbound && len(v.Binds) > 0 {
+		return false
+	}
+	for k, value := range f.tags {
+		if tag, ok := v.Opts[volumeTagPrefix+k]; !ok || tag != value {
+			return false
+		}
+	}
+	return f.opts.matches(v.Opts)
+}
+
+// matchesQuery reports whether v matches the filters sent to the server by
+// queryString.
+func (f *volumeFilter) matchesQuery(v volumeTypes.Volume) bool {
+	if f.name != "" && !strings.Contains(v.Name, f.name) {
+		return false
+	}
+	if f.pool != "" && v.Pool != f.pool {
+		return false
+	}
+	if f.plan != "" && v.Plan.Name != f.plan {
+		return false
+	}
+	if len(f.teams) > 0 && !containsString(f.teams, v.TeamOwner) {
+		return false
+	}
+	return true
+}
+
+func (f *volumeFilter) queryString() (url.Values, error) {
+	result := make(url.Values)
+	if f.name != "" {
+		result.Set("name", f.name)
+	}
+	if len(f.teams) > 0 {
+		result["teamOwner"] = f.teams
+	}
+	if f.pool != "" {
+		result.Set("pool", f.pool)
+	}
+	if f.plan != "" {
+		result.Set("plan", f.plan)
+	}
+	return result, nil
+}
+
+// volumeListNoSort keeps volumes in the order returned by the API, which
+// allows volume-list to display them as they are received.
+const volumeListNoSort = "none"
+
+var volumeListSortFields = []string{"name", "plan", "pool", "team", volumeListNoSort}
+var volumeListGroupFields = []string{"pool", "team", "plan"}
+
+type volumeListField struct {
+	header string
+	value  func(c *VolumeList, v volumeTypes.Volume) string
+}
+
+// volumeListFields are the columns that can be selected with the --fields
+// flag of volume-list.
+var volumeListFields = map[string]volumeListField{
+	"name": {"Name", func(c *VolumeList, v volumeTypes.Volume) string { return v.Name }},
+	"plan": {"Plan", func(c *VolumeList, v volumeTypes.Volume) string { return v.Plan.Name }},
+	"pool": {"Pool", func(c *VolumeList, v volumeTypes.Volume) string { return v.Pool }},
+	"team": {"Team", func(c *VolumeList, v volumeTypes.Volume) string { return v.TeamOwner }},
+	"binds": {"Binds", func(c *VolumeList, v volumeTypes.Volume) string {
+		return strconv.Itoa(len(v.Binds))
+	}},
+	"provisioner": {"Provisioner", func(c *VolumeList, v volumeTypes.Volume) string {
+		return valueOrDash(c.planProvisioners[v.Plan.Name])
+	}},
+	"capacity": {"Capacity", func(c *VolumeList, v volumeTypes.Volume) string {
+		return valueOrDash(volumeCapacity(v))
+	}},
+	"mountpoints": {"Mount Points", func(c *VolumeList, v volumeTypes.Volume) string {
+		mountPoints := make([]string, 0, len(v.Binds))
+		for _, b := range v.Binds {
+			mountPoints = append(mountPoints, b.ID.App+":"+b.ID.MountPoint)
+		}
+		return valueOrDash(strings.Join(mountPoints, "\n"))
+	}},
+}
+
+var (
+	volumeListDefaultFields = []string{"name", "plan", "pool", "team", "binds"}
+	volumeListWideFields    = []string{"provisioner", "capacity"}
+)
+
+type VolumeList struct {
+	fs         *gnuflag.FlagSet
+	filter     volumeFilter
+	simplified bool
+	json       bool
+	jsonl      bool
+	sortBy     string
+	reverse    bool
+	noHeader   bool
+	wide       bool
+	mounts     bool
+	count      bool
+	exists     bool
+	retries    int
+	limit      int
+	page       int
+	noColor    bool
+	fields     string
+	selectPath string
+	groupBy    string
+	template   string
+	clientOnly bool
+	olderThan  time.Duration
+	createdBy  string
+	enrich     bool
+	conn       volumeConn
+
+	planProvisioners map[string]string
+	tmpl             *template.Template
+	// sentQuery and ignored are used to detect servers that don't honor
+	// the filters in the query string.
+	sentQuery bool
+	ignored   int
+	// unknownAge is set when --older-than is used and the server doesn't
+	// expose the creation time of some volumes.
+	unknownAge bool
+	// unknownOwner is set when --created-by is used and the creator of
+	// some volumes couldn't be found.
+	unknownOwner bool
+}
+
+func (c *VolumeList) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-list",
+		Usage: "volume list [--bound|--unbound] [--sort name|plan|pool|team|none [--reverse]] [--fields name,pool,...] [--group-by pool|team|plan] [--select .Plan.Opts.capacity] [--template <template>]",
+		Desc: `Lists existing persistent volumes.
+
+The --fields flag takes a comma-separated list of the columns to display, in
+order. Valid fields are: binds, capacity, mountpoints, name, plan, pool,
+provisioner and team. It takes precedence over --wide.
+
+The --show-mountpoints flag adds a column listing the mount points of each
+volume as app:mountpoint pairs, one per line. With --no-header, the pairs are
+separated by commas to keep each volume in a single line.
+
+The --reverse flag sorts the volumes in descending order of the --sort
+column, in every output mode, including -q and --json. It can't be used with
+--sort none or --jsonl.
+
+With --sort none, volumes are displayed in the order returned by the API. In
+this case, the -q and --no-header modes print each volume as soon as it's
+received, which is useful for very large lists.
+
+The --jsonl flag prints each volume as a JSON object in its own line, in the
+order returned by the API, which is easier to process in pipelines than the
+single document printed by --json.
+
+The --group-by flag displays a separate table for each pool, team or plan,
+preceded by a line naming the group. With --json, the volumes are nested
+under the name of their group.
+
+The --select flag prints a single value of each volume, one per line, given
+by a dotted path of fields and map keys, such as .Name or .Plan.Opts.capacity.
+Field names are case insensitive. An empty line is printed for volumes that
+don't have the value. Lists and objects are printed as JSON.
+
+The --template flag formats each volume using a Go template (see
+https://pkg.go.dev/text/template), such as '{{.Name}} {{.Pool}}'. The fields
+available are the ones displayed by --json. A line break is added after each
+volume when missing.
+
+Filters are sent to the server and applied again on the client side. When
+running with --verbosity, volume-list reports whether the server ignored some
+of them. The --client-filter-only flag prevents sending the filters at all,
+which helps with servers whose filtering is broken.
+
+The --older-than flag relies on the creation time of the volumes, which isn't
+exposed by every server version. Volumes whose creation time is unknown are
+kept in the list, and a note is displayed.
+
+The --created-by flag keeps only the volumes created by the given user, such
+as --created-by admin@example.com. It relies on the creator of the volumes,
+which isn't exposed by every server version. Volumes whose creator is unknown
+are left out of the list, and a note is displayed. With --enrich-owner, the
+creator of those volumes is looked up in their volume.create event instead.
+This takes one extra request per volume matching the other filters, which
+are applied first, so combine it with them on long lists.
+
+The --exists flag prints nothing and exits with status 0 when at least one
+volume matches the filters, or 1 otherwise, which is useful in scripts.
+
+The --opt flag keeps only the volumes having the given option, such as
+--opt storage-class=fast. Given only a key, as in --opt storage-class, any
+value matches. It may be given multiple times, and every option must match.
+
+The --team flag takes a comma-separated list of teams, such as
+--team team1,team2, to display the volumes owned by any of them.
+
+When the pool or team filters are omitted, the values of the TSURU_POOL and
+TSURU_TEAM environment variables are used. Explicit flags always take
+precedence over the environment.
+
+The default output format can be set with the volume.output setting (table,
+json or jsonl) in ~/.tsuru/config.json. It's ignored when any of the --json,
+--jsonl, -q, --count, --exists, --no-header, --select or --template flags is
+given.`,
+		MinArgs: 0,
+		MaxArgs: 0,
+	}
+}
+
+func (c *VolumeList) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("volume-list", gnuflag.ExitOnError)
+		c.filter.addFlags(c.fs)
+		c.fs.BoolVar(&c.simplified, "q", false, "Display only volumes name")
+		c.fs.BoolVar(&c.json, "json", false, "Display in JSON format")
+		c.fs.BoolVar(&c.jsonl, "jsonl", false, "Display in JSON Lines format, one volume per line")
+		c.fs.StringVar(&c.sortBy, "sort", "name", "Sort volumes by column (name, plan, pool, team or none)")
+		c.fs.BoolVar(&c.reverse, "reverse", false, "Sort volumes in descending order")
+		c.fs.BoolVar(&c.noHeader, "no-header", false, "Display only data rows, with tab-separated columns")
+		c.fs.BoolVar(&c.wide, "wide", false, "Display extra columns: provisioner and capacity")
+		c.fs.BoolVar(&c.mounts, "show-mountpoints", false, "Display a column with the app:mountpoint pairs of each volume")
+		c.fs.BoolVar(&c.count, "count", false, "Display only the number of volumes matching the filters")
+		c.fs.BoolVar(&c.exists, "exists", false, "Display nothing, exiting with status 0 if any volume matches the filters and 1 otherwise")
+		c.fs.IntVar(&c.retries, "retries", 2, volumeRetriesDesc)
+		c.fs.IntVar(&c.limit, "limit", 0, "Display at most this number of volumes (0 means no limit)")
+		c.fs.IntVar(&c.page, "page", 1, "With --limit, the page of results to display, starting at 1")
+		c.fs.BoolVar(&c.noColor, "no-color", false, "Don't colorize the table by bind status")
+		c.fs.StringVar(&c.fields, "fields", "", "Comma-separated list of the columns to display, in order")
+		c.fs.StringVar(&c.groupBy, "group-by", "", "Display a separate table for each pool, team or plan")
+		c.fs.StringVar(&c.template, "template", "", "Format each volume using a Go template, e.g. '{{.Name}} {{.Pool}}'")
+		c.fs.StringVar(&c.selectPath, "select", "", "Display only the value at the given dotted path (e.g. .Plan.Opts.capacity) for each volume")
+		c.fs.BoolVar(&c.clientOnly, "client-filter-only", false, "Don't send the filters to the server, applying them only on the client side")
+		c.fs.DurationVar(&c.olderThan, "older-than", 0, "Display only volumes created longer than this duration ago (e.g. 720h)")
+		c.fs.StringVar(&c.createdBy, "created-by", "", "Display only volumes created by this user")
+		c.fs.BoolVar(&c.enrich, "enrich-owner", false, "With --created-by, look up the creator of the volumes in their events when the server doesn't expose it (one request per volume)")
+		c.conn.addFlags(c.fs)
+	}
+	return c.fs
+}
+
+// pageBounds returns the start and end indexes of the page being displayed
+// out of total volumes. A negative total means the number of volumes isn't
+// known yet, in which case the bounds aren't capped.
+func (c *VolumeList) pageBounds(total int) (int, int) {
+	if c.limit <= 0 {
+		return 0, total
+	}
+	start := (c.page - 1) * c.limit
+	end := start + c.limit
+	if total < 0 {
+		return start, end
+	}
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+func (c *VolumeList) sortField() (string, error) {
+	if c.sortBy == "" {
+		return "name", nil
+	}
+	if !containsString(volumeListSortFields, c.sortBy) {
+		return "", fmt.Errorf("invalid sort column %q, valid options are: %s", c.sortBy, strings.Join(volumeListSortFields, ", "))
+	}
+	return c.sortBy, nil
+}
+
+// listFields returns the names of the columns to display, either the ones
+// given in --fields or the default layout, followed by the mount points when
+// --show-mountpoints is set.
+func (c *VolumeList) listFields() ([]string, error) {
+	fields, err := c.selectedFields()
+	if err != nil {
+		return nil, err
+	}
+	if c.mounts && !containsString(fields, "mountpoints") {
+		fields = append(fields, "mountpoints")
+	}
+	return fields, nil
+}
+
+func (c *VolumeList) selectedFields() ([]string, error) {
+	if c.fields == "" {
+		fields := append([]string{}, volumeListDefaultFields...)
+		if c.wide {
+			fields = append(fields, volumeListWideFields...)
+		}
+		return fields, nil
+	}
+	var fields []string
+	for _, f := range strings.Split(c.fields, ",") {
+		f = strings.TrimSpace(f)
+		if _, ok := volumeListFields[f]; !ok {
+			valid := make([]string, 0, len(volumeListFields))
+			for name := range volumeListFields {
+				valid = append(valid, name)
+			}
+			sort.Strings(valid)
+			return nil, fmt.Errorf("invalid field %q, valid options are: %s", f, strings.Join(valid, ", "))
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func (c *VolumeList) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeError(ctx, &err, &c.json, &c.jsonl)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	c.filter.pool = flagOrEnv(c.filter.pool, volumePoolEnv)
+	c.filter.teamOwner = flagOrEnv(c.filter.teamOwner, volumeTeamEnv)
+	if err := c.filter.validate(); err != nil {
+		return err
+	}
+	output, err := configOutput(c.fs, []string{"json", "jsonl", "q", "count", "exists", "no-header", "select", "template"}, "table", "json", "jsonl")
+	if err != nil {
+		return err
+	}
+	c.json = c.json || output == "json"
+	c.jsonl = c.jsonl || output == "jsonl"
+	if c.json && c.jsonl {
+		return errors.New("the --json and --jsonl flags are mutually exclusive")
+	}
+	if c.limit < 0 {
+		return errors.New("the --limit flag must not be negative")
+	}
+	if c.olderThan < 0 {
+		return errors.New("the --older-than flag must not be negative")
+	}
+	if c.enrich && c.createdBy == "" {
+		return errors.New("the --enrich-owner flag can only be used with --created-by")
+	}
+	if c.groupBy != "" {
+		if !containsString(volumeListGroupFields, c.groupBy) {
+			return fmt.Errorf("invalid group %q, valid options are: %s", c.groupBy, strings.Join(volumeListGroupFields, ", "))
+		}
+		if c.jsonl || c.count || c.simplified || c.noHeader || c.selectPath != "" {
+			return errors.New("the --group-by flag can't be used with --jsonl, --count, --no-header, --select or -q")
+		}
+	}
+	if c.selectPath != "" {
+		if c.json || c.jsonl || c.count || c.simplified {
+			return errors.New("the --select flag can't be used with --json, --jsonl, --count or -q")
+		}
+		if _, err = parseSelectPath(c.selectPath); err != nil {
+			return err
+		}
+	}
+	if c.template != "" {
+		if c.json || c.jsonl || c.count || c.simplified || c.selectPath != "" || c.groupBy != "" {
+			return errors.New("the --template flag can't be used with --json, --jsonl, --count, --select, --group-by or -q")
+		}
+		c.tmpl, err = template.New("volume").Parse(c.template)
+		if err != nil {
+			return fmt.Errorf("invalid --template: %w", err)
+		}
+	}
+	if c.exists && (c.json || c.jsonl || c.count || c.simplified || c.noHeader || c.selectPath != "" || c.groupBy != "" || c.tmpl != nil) {
+		return errors.New("the --exists flag can't be used with --json, --jsonl, --count, --no-header, --select, --group-by, --template or -q")
+	}
+	if c.limit > 0 && c.page < 1 {
+		return errors.New("the --page flag must be greater than zero")
+	}
+	sortField, err := c.sortField()
+	if err != nil {
+		return err
+	}
+	if c.reverse && sortField == volumeListNoSort {
+		return errors.New("the --reverse flag can't be used with --sort none")
+	}
+	if c.reverse && c.jsonl {
+		return errors.New("the --reverse flag can't be used with --jsonl")
+	}
+	fields, err := c.listFields()
+	if err != nil {
+		return err
+	}
+	qs := url.Values{}
+	if !c.clientOnly {
+		qs, err = c.filter.queryString()
+		if err != nil {
+			return err
+		}
+	}
+	c.sentQuery = len(qs) > 0
+	client = volumeReadClient(ctx, client, c.retries)
+	defer c.reportIgnoredFilters(ctx, client)
+	defer c.reportUnknownAge(ctx)
+	defer c.reportUnknownOwner(ctx)
+	if c.count {
+		var count int
+		_, err = c.conn.streamVolumes(client, qs, func(item volumeListItem) error {
+			keep, err := c.keepItem(client, item)
+			if err != nil {
+				return err
+			}
+			if keep {
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(ctx.Stdout, count)
+		return nil
+	}
+	if c.exists {
+		var found bool
+		_, err = c.conn.streamVolumes(client, qs, func(item volumeListItem) error {
+			if found {
+				return nil
+			}
+			keep, err := c.keepItem(client, item)
+			found = keep
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if !found {
+			return cmd.ErrAbortCommand
+		}
+		return nil
+	}
+	if c.streaming(sortField) {
+		return c.stream(ctx, client, qs, fields)
+	}
+	volumes := []volumeTypes.Volume{}
+	creators := map[string]string{}
+	found, err := c.conn.streamVolumes(client, qs, func(item volumeListItem) error {
+		if c.createdBefore(item) {
+			volumes = append(volumes, item.Volume)
+			creators[item.Name] = item.CreatedBy
 		}
 		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(ctx.Stdout, "No volumes available.")
+		return nil
+	}
+	volumes = c.clientSideFilter(volumes)
+	volumes, err = c.filterCreatedBy(client, volumes, creators)
+	if err != nil {
+		return err
 	}
+	if containsString(fields, "provisioner") {
+		plans, err := c.conn.listVolumePlans(client)
+		if err != nil {
+			return err
+		}
+		c.planProvisioners = planProvisioners(plans)
+	}
+	return c.render(ctx, volumes, sortField, fields)
+}
 
-	if c.json {
-		return formatter.JSON(ctx.Stdout, volumes)
+// streaming reports whether volumes can be written as they are decoded,
+// which is only possible when they don't need to be sorted and the output
+// isn't a table or a JSON document. JSON Lines are never sorted.
+func (c *VolumeList) streaming(sortField string) bool {
+	if c.jsonl {
+		return true
+	}
+	return sortField == volumeListNoSort && !c.json && (c.simplified || c.noHeader || c.selectPath != "" || c.tmpl != nil)
+}
+
+func (c *VolumeList) stream(ctx *cmd.Context, client *cmd.Client, qs url.Values, fields []string) error {
+	if containsString(fields, "provisioner") && !c.simplified && !c.jsonl {
+		plans, err := c.conn.listVolumePlans(client)
+		if err != nil {
+			return err
+		}
+		c.planProvisioners = planProvisioners(plans)
+	}
+	start, end := c.pageBounds(-1)
+	var index int
+	encoder := json.NewEncoder(ctx.Stdout)
+	found, err := c.conn.streamVolumes(client, qs, func(item volumeListItem) error {
+		v := item.Volume
+		keep, err := c.keepItem(client, item)
+		if err != nil {
+			return err
+		}
+		if !keep {
+			return nil
+		}
+		index++
+		if index <= start || (end >= 0 && index > end) {
+			return nil
+		}
+		if c.jsonl {
+			return encoder.Encode(v)
+		}
+		if c.selectPath != "" {
+			return printSelected(ctx.Stdout, v, c.selectPath)
+		}
+		if c.tmpl != nil {
+			return executeVolumeTemplate(ctx.Stdout, c.tmpl, v)
+		}
+		if c.simplified {
+			_, err := fmt.Fprintln(ctx.Stdout, v.Name)
+			return err
+		}
+		_, err = fmt.Fprintln(ctx.Stdout, c.plainRow(v, fields))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !found && !c.jsonl {
+		fmt.Fprintln(ctx.Stdout, "No volumes available.")
 	}
+	return nil
+}
 
+// table renders volumes as a table with the given columns.
+func (c *VolumeList) table(ctx *cmd.Context, volumes []volumeTypes.Volume, fields []string) string {
 	tbl := tablecli.NewTable()
-	tbl.Headers = tablecli.Row{"Name", "Plan", "Pool", "Team"}
+	for _, f := range fields {
+		tbl.Headers = append(tbl.Headers, volumeListFields[f].header)
+	}
 	tbl.LineSeparator = true
+	colorize := c.useColors(ctx)
 	for _, v := range volumes {
-		tbl.AddRow(tablecli.Row{
-			v.Name,
-			v.Plan.Name,
-			v.Pool,
-			v.TeamOwner,
-		})
+		row := c.row(v, fields)
+		if colorize {
+			row = colorVolumeRow(row, len(v.Binds) > 0)
+		}
+		tbl.AddRow(row)
+	}
+	return tbl.String()
+}
+
+// groupVolumes splits volumes by the value of the --group-by field, keeping
+// their order within each group. It also returns the sorted group names.
+func (c *VolumeList) groupVolumes(volumes []volumeTypes.Volume) (map[string][]volumeTypes.Volume, []string) {
+	value := volumeListFields[c.groupBy].value
+	groups := map[string][]volumeTypes.Volume{}
+	var names []string
+	for _, v := range volumes {
+		name := value(c, v)
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], v)
+	}
+	sort.Strings(names)
+	return groups, names
+}
+
+// parseSelectPath splits a --select path like .Plan.Opts.capacity into its
+// components.
+func parseSelectPath(path string) ([]string, error) {
+	parts := strings.Split(path, ".")
+	if len(parts) < 2 || parts[0] != "" {
+		return nil, fmt.Errorf("invalid --select path %q, it must be a dotted path like .Plan.Opts.capacity", path)
+	}
+	for _, p := range parts[1:] {
+		if p == "" {
+			return nil, fmt.Errorf("invalid --select path %q, it must be a dotted path like .Plan.Opts.capacity", path)
+		}
+	}
+	return parts[1:], nil
+}
+
+// selectVolumeValue returns the value found in v following path, which is
+// matched against the fields of the volume as they are encoded in JSON. It
+// returns nil when the path doesn't exist.
+func selectVolumeValue(v volumeTypes.Volume, path []string) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err = json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	for _, key := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		next, ok := m[key]
+		if !ok {
+			for k, val := range m {
+				if strings.EqualFold(k, key) {
+					next = val
+					break
+				}
+			}
+		}
+		value = next
+	}
+	return value, nil
+}
+
+// printSelected writes the value of v at selectPath to w in its own line.
+func printSelected(w io.Writer, v volumeTypes.Volume, selectPath string) error {
+	path, err := parseSelectPath(selectPath)
+	if err != nil {
+		return err
+	}
+	value, err := selectVolumeValue(v, path)
+	if err != nil {
+		return err
+	}
+	var text string
+	switch value := value.(type) {
+	case string:
+		text = value
+	case nil:
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		text = string(data)
+	}
+	_, err = fmt.Fprintln(w, text)
+	return err
+}
+
+func (c *VolumeList) row(v volumeTypes.Volume, fields []string) tablecli.Row {
+	row := make(tablecli.Row, 0, len(fields))
+	for _, f := range fields {
+		row = append(row, volumeListFields[f].value(c, v))
+	}
+	return row
+}
+
+// plainRow returns the columns of v separated by tabs, as displayed by
+// --no-header. Multi-line cells are joined by commas.
+func (c *VolumeList) plainRow(v volumeTypes.Volume, fields []string) string {
+	row := c.row(v, fields)
+	for i := range row {
+		row[i] = strings.ReplaceAll(row[i], "\n", ",")
+	}
+	return strings.Join(row, "\t")
+}
+
+// useColors reports whether the table should be colorized, which only
+// happens when writing to a terminal and colors weren't disabled with
+// --no-color or the NO_COLOR environment variable.
+func (c *VolumeList) useColors(ctx *cmd.Context) bool {
+	return !c.noColor && colorsEnabled(ctx.Stdout)
+}
+
+// colorsEnabled reports whether output written to w may be colorized: w
+// must be a terminal and colors must not be disabled by the NO_COLOR
+// environment variable.
+func colorsEnabled(w io.Writer) bool {
+	return os.Getenv("NO_COLOR") == "" && isTerminal(w)
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorVolumeRow colors a volume-list row according to the bind status of
+// its volume: green for bound volumes and yellow for unbound ones.
+func colorVolumeRow(row tablecli.Row, bound bool) tablecli.Row {
+	color := "green"
+	if !bound {
+		color = "yellow"
+	}
+	colored := make(tablecli.Row, len(row))
+	for i, v := range row {
+		colored[i] = cmd.Colorfy(v, color, "", "")
+	}
+	return colored
+}
+
+// planProvisioners maps each plan name to the provisioners that offer it.
+func planProvisioners(plans map[string][]volumeTypes.VolumePlan) map[string]string {
+	provisioners := map[string][]string{}
+	for provisioner, provPlans := range plans {
+		for _, p := range provPlans {
+			provisioners[p.Name] = append(provisioners[p.Name], provisioner)
+		}
+	}
+	result := make(map[string]string, len(provisioners))
+	for name, provs := range provisioners {
+		sort.Strings(provs)
+		result[name] = strings.Join(provs, ", ")
+	}
+	return result
+}
+
+var volumeCapacityKeys = []string{"capacity", "size"}
+
+// volumeCapacity returns the capacity of a volume, looking for the usual
+// capacity options in the volume opts and then in its plan opts.
+func volumeCapacity(v volumeTypes.Volume) string {
+	for _, key := range volumeCapacityKeys {
+		if value := v.Opts[key]; value != "" {
+			return value
+		}
+	}
+	for _, key := range volumeCapacityKeys {
+		if value, ok := v.Plan.Opts[key]; ok {
+			return fmt.Sprintf("%v", value)
+		}
+	}
+	return ""
+}
+
+var volumeCapacityUnits = []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+const volumeRawDesc = "Display capacities as returned by the API, without converting byte counts to binary units"
+
+// humanizeCapacity renders capacities given as a plain number of bytes in
+// binary units, such as 10.0 GiB. Other values, such as 10Gi, are returned
+// unchanged.
+func humanizeCapacity(value string) string {
+	bytes, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return value
+	}
+	if bytes < 1024 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	size := float64(bytes) / 1024
+	unit := 0
+	for size >= 1024 && unit < len(volumeCapacityUnits)-1 {
+		size /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", size, volumeCapacityUnits[unit])
+}
+
+// listVolumes fetches the volumes matching qs from the API. It returns a nil
+// slice when the server reports that no volumes are available.
+func (t *volumeConn) listVolumes(client *cmd.Client, qs url.Values) ([]volumeTypes.Volume, error) {
+	volumes := []volumeTypes.Volume{}
+	found, err := t.streamVolumes(client, qs, func(item volumeListItem) error {
+		volumes = append(volumes, item.Volume)
+		return nil
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return volumes, nil
+}
+
+// volumeListItem is a volume as returned by the volume list endpoint. The
+// creation time is only present in newer server versions.
+type volumeListItem struct {
+	volumeTypes.Volume
+	CreatedAt *time.Time `json:",omitempty"`
+	CreatedBy string     `json:",omitempty"`
+}
+
+// streamVolumes fetches the volumes matching qs from the API, decoding the
+// response one volume at a time and calling fn for each of them. It returns
+// false when the server reports that no volumes are available.
+func (t *volumeConn) streamVolumes(client *cmd.Client, qs url.Values, fn func(volumeListItem) error) (bool, error) {
+	u, err := t.volumeURL(fmt.Sprintf("/volumes?%s", qs.Encode()))
+	if err != nil {
+		return false, err
+	}
+	request, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return false, err
+	}
+	rsp, err := client.Do(request)
+	if err != nil {
+		return false, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode == http.StatusNoContent {
+		return false, nil
+	}
+	decoder := json.NewDecoder(rsp.Body)
+	if err = expectDelim(decoder, '['); err != nil {
+		return false, err
+	}
+	for decoder.More() {
+		var item volumeListItem
+		if err = decoder.Decode(&item); err != nil {
+			return false, err
+		}
+		if err = fn(item); err != nil {
+			return false, err
+		}
+	}
+	return true, expectDelim(decoder, ']')
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("invalid volume list: expected %q, got %v", delim, token)
+	}
+	return nil
+}
+
+func (c *VolumeList) clientSideFilter(volumes []volumeTypes.Volume) []volumeTypes.Volume {
+	result := make([]volumeTypes.Volume, 0, len(volumes))
+	for _, v := range volumes {
+		if c.matches(v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// matches applies the filters to v, counting the volumes returned by the
+// server even though they don't match the filters sent to it.
+func (c *VolumeList) matches(v volumeTypes.Volume) bool {
+	if c.sentQuery && !c.filter.matchesQuery(v) {
+		c.ignored++
+	}
+	return c.filter.matches(v)
+}
+
+// createdBefore reports whether the volume was created before the cutoff
+// given by --older-than. Volumes whose creation time is unknown are kept.
+func (c *VolumeList) createdBefore(item volumeListItem) bool {
+	if c.olderThan == 0 {
+		return true
+	}
+	if item.CreatedAt == nil {
+		c.unknownAge = true
+		return true
+	}
+	return item.CreatedAt.Before(time.Now().Add(-c.olderThan))
+}
+
+// keepItem applies every filter to item. The creator of the volume is only
+// checked once the other filters match, as looking it up with
+// --enrich-owner takes one request per volume.
+func (c *VolumeList) keepItem(client *cmd.Client, item volumeListItem) (bool, error) {
+	if !c.createdBefore(item) || !c.matches(item.Volume) {
+		return false, nil
+	}
+	return c.createdByUser(client, item)
+}
+
+// filterCreatedBy keeps the volumes created by the user given by
+// --created-by, with creators holding the creator returned by the server
+// for each volume, if any. It's meant to run after the other filters.
+func (c *VolumeList) filterCreatedBy(client *cmd.Client, volumes []volumeTypes.Volume, creators map[string]string) ([]volumeTypes.Volume, error) {
+	if c.createdBy == "" {
+		return volumes, nil
+	}
+	result := make([]volumeTypes.Volume, 0, len(volumes))
+	for _, v := range volumes {
+		keep, err := c.createdByUser(client, volumeListItem{Volume: v, CreatedBy: creators[v.Name]})
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+// createdByUser reports whether the volume was created by the user given by
+// --created-by. When the server doesn't expose the creator of the volume,
+// it's looked up in the events of the volume if --enrich-owner is set.
+// Volumes whose creator is unknown are left out.
+func (c *VolumeList) createdByUser(client *cmd.Client, item volumeListItem) (bool, error) {
+	if c.createdBy == "" {
+		return true, nil
+	}
+	owner := item.CreatedBy
+	if owner == "" && c.enrich {
+		var err error
+		owner, err = volumeCreator(client, item.Name)
+		if err != nil {
+			return false, err
+		}
+	}
+	if owner == "" {
+		c.unknownOwner = true
+		return false, nil
+	}
+	return owner == c.createdBy, nil
+}
+
+// volumeCreator returns the name of the owner of the event that created
+// the volume named volumeName, or an empty string when there's no such
+// event.
+func volumeCreator(client *cmd.Client, volumeName string) (string, error) {
+	evts, err := listVolumeEvents(client, volumeName, 0)
+	if err != nil {
+		return "", err
+	}
+	// Events are listed from the most recent to the oldest, the volume
+	// may have been removed and created again.
+	for i := range evts {
+		evt := &evts[i]
+		if evt.Kind.Name == "volume.create" && evt.Error == "" && !evt.Running {
+			return evt.Owner.Name, nil
+		}
+	}
+	return "", nil
+}
+
+func (c *VolumeList) reportUnknownOwner(ctx *cmd.Context) {
+	if !c.unknownOwner {
+		return
+	}
+	if c.enrich {
+		fmt.Fprintln(ctx.Stderr, "Note: the creator of some volumes couldn't be found in their events, they were left out of the list.")
+		return
+	}
+	fmt.Fprintln(ctx.Stderr, "Note: the server doesn't expose the creator of some volumes, they were left out of the list. Use --enrich-owner to look it up in their events.")
+}
+
+func (c *VolumeList) reportUnknownAge(ctx *cmd.Context) {
+	if c.unknownAge {
+		fmt.Fprintln(ctx.Stderr, "Note: the server doesn't expose the creation time of some volumes, they were kept regardless of --older-than.")
+	}
+}
+
+// reportIgnoredFilters tells verbose users that the server didn't honor the
+// filters in the query string, which were applied on the client side.
+func (c *VolumeList) reportIgnoredFilters(ctx *cmd.Context, client *cmd.Client) {
+	if c.ignored == 0 || client.Verbosity == 0 {
+		return
+	}
+	fmt.Fprintf(ctx.Stderr, "The server returned %d volume(s) not matching the filters, they were filtered out on the client side. Use --client-filter-only to skip filtering on the server.\n", c.ignored)
+}
+
+func volumeBoundToApp(v volumeTypes.Volume, appName string) bool {
+	for _, b := range v.Binds {
+		if b.ID.App == appName {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *VolumeList) render(ctx *cmd.Context, volumes []volumeTypes.Volume, sortField string, fields []string) error {
+	total := len(volumes)
+	start, end := c.pageBounds(total)
+	// Volumes are sorted before being paged, so every output mode displays
+	// the same page for the same flags.
+	sorted := append([]volumeTypes.Volume{}, volumes...)
+	if sortField != volumeListNoSort {
+		sortValue := volumeListFields[sortField].value
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if c.reverse {
+				i, j = j, i
+			}
+			vi, vj := sortValue(c, sorted[i]), sortValue(c, sorted[j])
+			if vi != vj {
+				return vi < vj
+			}
+			return sorted[i].Name < sorted[j].Name
+		})
+	}
+	sorted = sorted[start:end]
+	if c.simplified {
+		for _, v := range sorted {
+			fmt.Fprintln(ctx.Stdout, v.Name)
+		}
+		return nil
+	}
+
+	if c.json {
+		if c.groupBy != "" {
+			groups, _ := c.groupVolumes(sorted)
+			return formatter.JSON(ctx.Stdout, groups)
+		}
+		return formatter.JSON(ctx.Stdout, sorted)
+	}
+
+	if c.selectPath != "" {
+		for _, v := range sorted {
+			if err := printSelected(ctx.Stdout, v, c.selectPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if c.tmpl != nil {
+		for _, v := range sorted {
+			if err := executeVolumeTemplate(ctx.Stdout, c.tmpl, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if c.noHeader {
+		for _, v := range sorted {
+			fmt.Fprintln(ctx.Stdout, c.plainRow(v, fields))
+		}
+		return nil
+	}
+
+	if c.groupBy != "" {
+		header := volumeListFields[c.groupBy].header
+		groups, names := c.groupVolumes(sorted)
+		for i, name := range names {
+			if i > 0 {
+				fmt.Fprintln(ctx.Stdout)
+			}
+			if name == "" {
+				name = "(none)"
+			}
+			fmt.Fprintf(ctx.Stdout, "%s: %s\n", header, name)
+			fmt.Fprint(ctx.Stdout, c.table(ctx, groups[names[i]], fields))
+		}
+	} else {
+		fmt.Fprint(ctx.Stdout, c.table(ctx, sorted, fields))
+	}
+	if c.limit > 0 {
+		if start == end {
+			fmt.Fprintf(ctx.Stdout, "Showing 0 of %d\n", total)
+		} else {
+			fmt.Fprintf(ctx.Stdout, "Showing %d-%d of %d\n", start+1, end, total)
+		}
+	}
+	return nil
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+type volumeBindEntry struct {
+	App        string `json:"app"`
+	Volume     string `json:"volume"`
+	MountPoint string `json:"mountPoint"`
+	ReadOnly   bool   `json:"readOnly"`
+	Pool       string `json:"pool"`
+}
+
+type VolumeBindList struct {
+	fs   *gnuflag.FlagSet
+	app  string
+	json bool
+	conn volumeConn
+}
+
+func (c *VolumeBindList) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-bind-list",
+		Usage: "volume bind list [-a/--app <appname>] [--json]",
+		Desc: `Lists the binds of every persistent volume, one line per app and mount
+point.`,
+		MinArgs: 0,
+		MaxArgs: 0,
+	}
+}
+
+func (c *VolumeBindList) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("volume-bind-list", gnuflag.ExitOnError)
+		c.fs.StringVar(&c.app, "app", "", "Filter binds by app name")
+		c.fs.StringVar(&c.app, "a", "", "Filter binds by app name")
+		c.fs.BoolVar(&c.json, "json", false, "Display in JSON format")
+		c.conn.addFlags(c.fs)
+	}
+	return c.fs
+}
+
+func (c *VolumeBindList) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeError(ctx, &err, &c.json)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	volumes, err := c.conn.listVolumes(client, url.Values{})
+	if err != nil {
+		return err
+	}
+	binds := []volumeBindEntry{}
+	for _, v := range volumes {
+		for _, b := range v.Binds {
+			if c.app != "" && b.ID.App != c.app {
+				continue
+			}
+			binds = append(binds, volumeBindEntry{
+				App:        b.ID.App,
+				Volume:     v.Name,
+				MountPoint: b.ID.MountPoint,
+				ReadOnly:   b.ReadOnly,
+				Pool:       v.Pool,
+			})
+		}
+	}
+	if c.json {
+		return formatter.JSON(ctx.Stdout, binds)
+	}
+	tbl := tablecli.NewTable()
+	tbl.Headers = tablecli.Row{"App", "Volume", "MountPoint", "Mode", "Pool"}
+	tbl.LineSeparator = true
+	for _, b := range binds {
+		mode := "rw"
+		if b.ReadOnly {
+			mode = "ro"
+		}
+		tbl.AddRow(tablecli.Row{b.App, b.Volume, b.MountPoint, mode, b.Pool})
+	}
+	tbl.SortByColumn(0, 1, 2)
+	fmt.Fprint(ctx.Stdout, tbl.String())
+	return nil
+}
+
+type VolumeInfo struct {
+	fs       *gnuflag.FlagSet
+	json     bool
+	retries  int
+	appsOnly bool
+	yamlSpec bool
+	watch    bool
+	interval time.Duration
+	template string
+	raw      bool
+	conn     volumeConn
+
+	provisioner string
+	tmpl        *template.Template
+	planCache   volumePlanCache
+	// provisioners maps plan names to their provisioners, fetched once
+	// even when the volume is watched.
+	provisioners map[string]string
+}
+
+func (c *VolumeInfo) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("volume-info", gnuflag.ContinueOnError)
+		c.fs.BoolVar(&c.json, "json", false, "Show JSON")
+		c.fs.IntVar(&c.retries, "retries", 2, volumeRetriesDesc)
+		c.fs.BoolVar(&c.appsOnly, "apps-only", false, "Display only the names of the apps bound to the volume, one per line")
+		c.fs.BoolVar(&c.yamlSpec, "show-yaml-spec", false, "Display only the fields needed to recreate the volume, as a YAML manifest for volume-import")
+		c.fs.BoolVar(&c.watch, "watch", false, "Refresh the volume periodically until interrupted")
+		c.fs.DurationVar(&c.interval, "interval", 2*time.Second, "With --watch, the time between refreshes")
+		c.fs.StringVar(&c.template, "template", "", "Format the volume using a Go template, e.g. '{{.Plan.Name}}'")
+		c.fs.BoolVar(&c.raw, "raw", false, volumeRawDesc)
+		c.conn.addFlags(c.fs)
+	}
+	return c.fs
+}
+
+func (c *VolumeInfo) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-info",
+		Usage: "volume info <volume> [--json|--apps-only|--template <template>|--show-yaml-spec] [--watch [--interval <duration>]]",
+		Desc: `Get a volume.
+
+Option values that are strings holding a JSON object or array are displayed
+indented, over several lines.
+
+A capacity given as a plain number of bytes is displayed in binary units, such
+as 10.0 GiB, unless --raw is used.
+
+A note is displayed below the binds when the volume isn't bound to any
+application, as it may be unused. Otherwise, the number of read-only and
+read-write binds is displayed below them.
+
+With --watch, the volume is fetched and displayed again every --interval
+until the command is interrupted with Ctrl-C. The screen is cleared before
+each refresh when writing to a terminal, otherwise the snapshots are
+appended to the output.
+
+The --template flag formats the volume using a Go template (see
+https://pkg.go.dev/text/template), such as '{{.Plan.Name}}' or
+'{{range .Binds}}{{.ID.App}} {{end}}'. The fields available are the ones
+displayed by --json. A line break is added to the output when missing.
+
+The --show-yaml-spec flag displays only the name, plan, pool, team and options
+of the volume, leaving out binds and fields set by the server. The output is
+a manifest that can be given to volume-import to recreate the volume, on
+another target for instance.
+
+The default output format can be set with the volume.output setting (table
+or json) in ~/.tsuru/config.json. It's ignored when --json, --apps-only,
+--template or --show-yaml-spec is given.`,
+		MinArgs: 1,
+		MaxArgs: 1,
+	}
+}
+
+func (c *VolumeInfo) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeError(ctx, &err, &c.json)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	output, err := configOutput(c.fs, []string{"json", "apps-only", "template", "show-yaml-spec"}, "table", "json")
+	if err != nil {
+		return err
+	}
+	c.json = c.json || output == "json"
+	if c.yamlSpec && (c.json || c.appsOnly || c.template != "") {
+		return errors.New("the --show-yaml-spec flag can't be used with --json, --apps-only or --template")
+	}
+	if c.template != "" {
+		if c.json || c.appsOnly {
+			return errors.New("the --template flag can't be used with --json or --apps-only")
+		}
+		c.tmpl, err = template.New("volume").Parse(c.template)
+		if err != nil {
+			return fmt.Errorf("invalid --template: %w", err)
+		}
+	}
+	client = volumeReadClient(ctx, client, c.retries)
+	if c.watch {
+		return c.watchVolume(ctx, client)
+	}
+	return c.show(ctx, client)
+}
+
+// volumeWatchInterrupt returns a channel notified when volume-info --watch
+// must stop, along with a function releasing it.
+var volumeWatchInterrupt = func() (<-chan os.Signal, func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt)
+	return ch, func() { signal.Stop(ch) }
+}
+
+const volumeClearScreen = "\033[H\033[2J"
+
+func (c *VolumeInfo) watchVolume(ctx *cmd.Context, client *cmd.Client) error {
+	if c.interval <= 0 {
+		return errors.New("the --interval flag must be greater than zero")
+	}
+	interrupt, stop := volumeWatchInterrupt()
+	defer stop()
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	terminal := isTerminal(ctx.Stdout)
+	for {
+		// Each snapshot is rendered before clearing the screen, so the
+		// previous one stays visible while the volume is fetched.
+		var buf bytes.Buffer
+		snapshotCtx := *ctx
+		snapshotCtx.Stdout = &buf
+		if err := c.show(&snapshotCtx, client); err != nil {
+			return err
+		}
+		if terminal {
+			fmt.Fprint(ctx.Stdout, volumeClearScreen)
+		}
+		fmt.Fprintf(ctx.Stdout, "Every %s: volume info %s\t%s\n\n", c.interval, ctx.Args[0], time.Now().Format(time.RFC1123))
+		ctx.Stdout.Write(buf.Bytes())
+		select {
+		case <-interrupt:
+			return nil
+		case <-ticker.C:
+			if !terminal {
+				fmt.Fprintln(ctx.Stdout)
+			}
+		}
+	}
+}
+
+func (c *VolumeInfo) show(ctx *cmd.Context, client *cmd.Client) error {
+	volume, err := c.conn.getVolume(client, ctx.Args[0])
+	if err != nil {
+		return err
+	}
+	if volume == nil {
+		fmt.Fprintln(ctx.Stdout, "No volumes available.")
+		return nil
+	}
+
+	if c.json {
+		return formatter.JSON(ctx.Stdout, volume)
+	}
+
+	if c.appsOnly {
+		for _, app := range volumeBoundApps(volume.Binds) {
+			fmt.Fprintln(ctx.Stdout, app)
+		}
+		return nil
+	}
+
+	if c.tmpl != nil {
+		return executeVolumeTemplate(ctx.Stdout, c.tmpl, volume)
+	}
+
+	if c.yamlSpec {
+		data, err := yaml.Marshal([]volumeSpec{newVolumeSpec(volume.Volume)})
+		if err != nil {
+			return err
+		}
+		_, err = ctx.Stdout.Write(data)
+		return err
+	}
+
+	c.provisioner = c.planProvisioner(client, volume.Plan.Name)
+	return c.render(ctx, *volume)
+}
+
+// planProvisioner returns the provisioner of the plan named planName. It
+// returns an empty string when the plans can't be listed, for instance when
+// the user isn't allowed to, in which case the provisioner isn't displayed.
+func (c *VolumeInfo) planProvisioner(client *cmd.Client, planName string) string {
+	if c.provisioners == nil {
+		// Failing to list the plans is not fatal, the provisioner is
+		// left out.
+		plans, _ := c.planCache.plans(&c.conn, client)
+		c.provisioners = planProvisioners(plans)
+	}
+	return c.provisioners[planName]
+}
+
+// volumeSpec holds the fields needed to create a volume. They are named as
+// in volumeTypes.Volume, so a list of specs can be read by volume-import.
+type volumeSpec struct {
+	Name      string
+	Plan      volumeSpecPlan
+	Pool      string
+	TeamOwner string
+	Opts      map[string]string `json:",omitempty"`
+}
+
+type volumeSpecPlan struct {
+	Name string
+}
+
+func newVolumeSpec(v volumeTypes.Volume) volumeSpec {
+	return volumeSpec{
+		Name:      v.Name,
+		Plan:      volumeSpecPlan{Name: v.Plan.Name},
+		Pool:      v.Pool,
+		TeamOwner: v.TeamOwner,
+		Opts:      v.Opts,
+	}
+}
+
+// executeVolumeTemplate writes the result of executing tmpl against volume to
+// w, ending it with a line break when missing.
+func executeVolumeTemplate(w io.Writer, tmpl *template.Template, volume interface{}) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, volume); err != nil {
+		return err
+	}
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// volumeData is a volume as returned by the API, with bind attributes that
+// are not part of volumeTypes.VolumeBind.
+type volumeData struct {
+	volumeTypes.Volume
+	Binds []volumeBindData
+}
+
+type volumeBindData struct {
+	volumeTypes.VolumeBind
+	SubPath     string `json:",omitempty"`
+	Propagation string `json:",omitempty"`
+}
+
+// getVolume fetches a single volume from the API. It returns nil when the
+// server reports that there is no such volume.
+func (t *volumeConn) getVolume(client *cmd.Client, volumeName string) (*volumeData, error) {
+	u, err := t.volumeURL("/volumes/" + volumeName)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	data, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var volume volumeData
+	err = json.Unmarshal(data, &volume)
+	if err != nil {
+		return nil, err
+	}
+	return &volume, nil
+}
+
+// volumeBoundApps returns the sorted names of the apps in binds, listing apps
+// that mount the volume more than once only once.
+func volumeBoundApps(binds []volumeBindData) []string {
+	seen := map[string]struct{}{}
+	var apps []string
+	for _, b := range binds {
+		if _, ok := seen[b.ID.App]; ok {
+			continue
+		}
+		seen[b.ID.App] = struct{}{}
+		apps = append(apps, b.ID.App)
+	}
+	sort.Strings(apps)
+	return apps
+}
+
+// volumeBindSummary describes how many apps and mount points use a volume.
+func volumeBindSummary(apps []string, binds []volumeBindData) string {
+	mountPoints := map[string]struct{}{}
+	for _, b := range binds {
+		mountPoints[b.ID.MountPoint] = struct{}{}
+	}
+	return fmt.Sprintf("%d app(s), %d mount point(s)", len(apps), len(mountPoints))
+}
+
+// formatOptValue renders an option value so that its type is apparent:
+// strings are quoted while booleans, numbers and null are displayed bare.
+// Lists and objects are displayed as JSON, which sorts the keys of nested
+// objects, keeping the output stable.
+func formatOptValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return "null"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// expandJSONOptValue pretty-prints string values holding a JSON object or
+// array, so that they span several lines of the table cell. Other values are
+// rendered by formatOptValue.
+func expandJSONOptValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		s = strings.TrimSpace(s)
+		if strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[") {
+			var buf bytes.Buffer
+			if err := json.Indent(&buf, []byte(s), "", "  "); err == nil {
+				return buf.String()
+			}
+		}
+	}
+	return formatOptValue(value)
+}
+
+func (c *VolumeInfo) render(ctx *cmd.Context, volume volumeData) error {
+	fmt.Fprintf(ctx.Stdout, "Name: %s\nPlan: %s\n", volume.Name, volume.Plan.Name)
+	if c.provisioner != "" {
+		fmt.Fprintf(ctx.Stdout, "Provisioner: %s\n", c.provisioner)
+	}
+	fmt.Fprintf(ctx.Stdout, "Pool: %s\nTeam: %s\n", volume.Pool, volume.TeamOwner)
+	if capacity := volumeCapacity(volume.Volume); capacity != "" {
+		if !c.raw {
+			capacity = humanizeCapacity(capacity)
+		}
+		fmt.Fprintf(ctx.Stdout, "Capacity: %s\n", capacity)
+	}
+	var hasSubPath, hasPropagation bool
+	for _, b := range volume.Binds {
+		hasSubPath = hasSubPath || b.SubPath != ""
+		hasPropagation = hasPropagation || b.Propagation != ""
+	}
+	bindTable := tablecli.NewTable()
+	bindTable.Headers = tablecli.Row([]string{"App", "MountPoint", "Mode"})
+	if hasSubPath {
+		bindTable.Headers = append(bindTable.Headers, "SubPath")
+	}
+	if hasPropagation {
+		bindTable.Headers = append(bindTable.Headers, "Propagation")
+	}
+	bindTable.LineSeparator = true
+	var readOnly int
+	for _, b := range volume.Binds {
+		mode := "rw"
+		if b.ReadOnly {
+			mode = "ro"
+			readOnly++
+		}
+		row := tablecli.Row([]string{b.ID.App, b.ID.MountPoint, mode})
+		if hasSubPath {
+			row = append(row, b.SubPath)
+		}
+		if hasPropagation {
+			row = append(row, b.Propagation)
+		}
+		bindTable.AddRow(row)
+	}
+	fmt.Fprintf(ctx.Stdout, "\nBinds:\n")
+	fmt.Fprint(ctx.Stdout, bindTable.String())
+	if len(volume.Binds) > 0 {
+		fmt.Fprintf(ctx.Stdout, "(%d read-only, %d read-write)\n", readOnly, len(volume.Binds)-readOnly)
+	}
+	if apps := volumeBoundApps(volume.Binds); len(apps) > 0 {
+		fmt.Fprintf(ctx.Stdout, "Apps: %s\n", strings.Join(apps, ", "))
+		fmt.Fprintf(ctx.Stdout, "Summary: %s\n", volumeBindSummary(apps, volume.Binds))
+	}
+	planOptsTable := tablecli.NewTable()
+	planOptsTable.Headers = []string{"Key", "Value"}
+	planOptsTable.LineSeparator = true
+	for k, v := range volume.Plan.Opts {
+		planOptsTable.AddRow([]string{k, expandJSONOptValue(v)})
+	}
+	planOptsTable.Sort()
+	fmt.Fprint(ctx.Stdout, "\nPlan Opts:\n")
+	fmt.Fprint(ctx.Stdout, planOptsTable.String())
+	tags, opts := splitVolumeTags(volume.Opts)
+	optsTable := tablecli.NewTable()
+	optsTable.Headers = []string{"Key", "Value"}
+	optsTable.LineSeparator = true
+	for k, v := range opts {
+		optsTable.AddRow([]string{k, expandJSONOptValue(v)})
+	}
+	optsTable.Sort()
+	fmt.Fprintf(ctx.Stdout, "\nOpts:\n")
+	fmt.Fprint(ctx.Stdout, optsTable.String())
+	if len(tags) > 0 {
+		tagsTable := tablecli.NewTable()
+		tagsTable.Headers = []string{"Key", "Value"}
+		tagsTable.LineSeparator = true
+		for k, v := range tags {
+			tagsTable.AddRow([]string{k, v})
+		}
+		tagsTable.Sort()
+		fmt.Fprintf(ctx.Stdout, "\nTags:\n")
+		fmt.Fprint(ctx.Stdout, tagsTable.String())
+	}
+	return nil
+}
+
+type VolumePlansList struct {
+	fs          *gnuflag.FlagSet
+	json        bool
+	yaml        bool
+	wide        bool
+	provisioner string
+	retries     int
+	conn        volumeConn
+}
+
+func (c *VolumePlansList) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-plan-list",
+		Usage: "volume plan list [--provisioner <provisioner>] [--wide] [--json|--yaml]",
+		Desc: `Lists existing volume plans.
+
+Plan options holding the minimum or maximum capacity of the volumes, such as
+min-capacity and max-capacity, are displayed in their own columns to make
+plans easier to compare. The --wide flag keeps them in the Opts column as
+well, displaying nested option values as JSON.
+
+The default output format can be set with the volume.output setting (table,
+json or yaml) in ~/.tsuru/config.json. It's ignored when --json or --yaml is
+given.`,
+		MinArgs: 0,
+		MaxArgs: 0,
+	}
+}
+
+func (c *VolumePlansList) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("volume-plan-list", gnuflag.ExitOnError)
+		c.fs.BoolVar(&c.json, "json", false, "Display in JSON format")
+		c.fs.BoolVar(&c.yaml, "yaml", false, "Display in YAML format")
+		c.fs.StringVar(&c.provisioner, "provisioner", "", "Display only plans of the given provisioner")
+		c.fs.BoolVar(&c.wide, "wide", false, "Display every plan option, with nested values as JSON")
+		c.fs.IntVar(&c.retries, "retries", 2, volumeRetriesDesc)
+		c.conn.addFlags(c.fs)
+	}
+	return c.fs
+}
+
+func (c *VolumePlansList) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeError(ctx, &err, &c.json)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	output, err := configOutput(c.fs, []string{"json", "yaml"}, "table", "json", "yaml")
+	if err != nil {
+		return err
+	}
+	c.json = c.json || output == "json"
+	c.yaml = c.yaml || output == "yaml"
+	if c.json && c.yaml {
+		return errors.New("the --json and --yaml flags are mutually exclusive")
+	}
+	client = volumeReadClient(ctx, client, c.retries)
+	plans, err := c.conn.listVolumePlans(client)
+	if err != nil {
+		return err
+	}
+	if plans == nil {
+		plans = map[string][]volumeTypes.VolumePlan{}
+	}
+	if c.provisioner != "" {
+		if len(plans[c.provisioner]) == 0 {
+			fmt.Fprintf(ctx.Stdout, "No plans for provisioner %s\n", c.provisioner)
+			return nil
+		}
+		plans = map[string][]volumeTypes.VolumePlan{c.provisioner: plans[c.provisioner]}
+	}
+	if c.json {
+		return formatter.JSON(ctx.Stdout, plans)
+	}
+	if c.yaml {
+		data, err := yaml.Marshal(plans)
+		if err != nil {
+			return err
+		}
+		_, err = ctx.Stdout.Write(data)
+		return err
+	}
+	return c.render(ctx, plans)
+}
+
+// listVolumePlans fetches the volume plans available in the API, keyed by
+// provisioner.
+func (t *volumeConn) listVolumePlans(client *cmd.Client) (map[string][]volumeTypes.VolumePlan, error) {
+	u, err := t.volumeURL("/volumeplans")
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	var plans map[string][]volumeTypes.VolumePlan
+	if rsp.StatusCode != http.StatusNoContent {
+		data, err := io.ReadAll(rsp.Body)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(data, &plans)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return plans, nil
+}
+
+const volumePlanCacheTTL = time.Minute
+
+// volumePlanCache holds the flags controlling the on-disk cache of volume
+// plans, shared by the plan validation of volume-create and by shell
+// completion.
+type volumePlanCache struct {
+	noCache bool
+	refresh bool
+}
+
+func (f *volumePlanCache) addFlags(fs *gnuflag.FlagSet) {
+	fs.BoolVar(&f.noCache, "no-cache", false, "Don't read or write the cache of volume plans")
+	fs.BoolVar(&f.refresh, "refresh-cache", false, "Fetch the volume plans from the API, refreshing their cache")
+}
+
+// plans returns the volume plans, reading them from the cache when it was
+// populated from the same target less than volumePlanCacheTTL ago.
+func (f *volumePlanCache) plans(conn *volumeConn, client *cmd.Client) (map[string][]volumeTypes.VolumePlan, error) {
+	if f.noCache {
+		return conn.listVolumePlans(client)
+	}
+	u, err := conn.volumeURL("/volumeplans")
+	if err != nil {
+		return nil, err
+	}
+	var plans map[string][]volumeTypes.VolumePlan
+	if !f.refresh && readCache("volumeplans", u, volumePlanCacheTTL, &plans) {
+		return plans, nil
+	}
+	plans, err = conn.listVolumePlans(client)
+	if err != nil {
+		return nil, err
+	}
+	writeCache("volumeplans", u, plans)
+	return plans, nil
+}
+
+// volumePlanMinCapacityKeys and volumePlanMaxCapacityKeys are the plan
+// options recognized as the capacity limits of the volumes, compared
+// ignoring case.
+var (
+	volumePlanMinCapacityKeys = []string{"min-capacity", "capacity-min", "min-size", "mincapacity"}
+	volumePlanMaxCapacityKeys = []string{"max-capacity", "capacity-max", "max-size", "maxcapacity", "capacity-limit"}
+)
+
+// planCapacityLimit returns the key and value of the option of opts that
+// is one of keys, or empty strings when there's none.
+func planCapacityLimit(opts map[string]interface{}, keys []string) (string, string) {
+	var found []string
+	for k := range opts {
+		if containsString(keys, strings.ToLower(k)) {
+			found = append(found, k)
+		}
+	}
+	if len(found) == 0 {
+		return "", ""
+	}
+	sort.Strings(found)
+	return found[0], humanizeCapacity(planOptString(opts[found[0]]))
+}
+
+// planOptString formats the value of a plan option, writing numbers
+// without exponents.
+func planOptString(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+func (c *VolumePlansList) render(ctx *cmd.Context, plans map[string][]volumeTypes.VolumePlan) error {
+	var hasLimits bool
+	for _, provPlans := range plans {
+		for _, p := range provPlans {
+			minKey, _ := planCapacityLimit(p.Opts, volumePlanMinCapacityKeys)
+			maxKey, _ := planCapacityLimit(p.Opts, volumePlanMaxCapacityKeys)
+			hasLimits = hasLimits || minKey != "" || maxKey != ""
+		}
+	}
+	tbl := tablecli.NewTable()
+	tbl.Headers = tablecli.Row{"Plan", "Provisioner"}
+	if hasLimits {
+		tbl.Headers = append(tbl.Headers, "Min Capacity", "Max Capacity")
+	}
+	tbl.Headers = append(tbl.Headers, "Opts")
+	tbl.LineSeparator = true
+	for provisioner, provPlans := range plans {
+		for _, p := range provPlans {
+			minKey, minCapacity := planCapacityLimit(p.Opts, volumePlanMinCapacityKeys)
+			maxKey, maxCapacity := planCapacityLimit(p.Opts, volumePlanMaxCapacityKeys)
+			var opts []string
+			for k, v := range p.Opts {
+				if !c.wide && (k == minKey || k == maxKey) {
+					continue
+				}
+				opts = append(opts, fmt.Sprintf("%s: %s", k, c.planOptValue(v)))
+			}
+			sort.Strings(opts)
+			row := tablecli.Row{p.Name, provisioner}
+			if hasLimits {
+				row = append(row, minCapacity, maxCapacity)
+			}
+			tbl.AddRow(append(row, strings.Join(opts, "\n")))
+		}
+	}
+	tbl.SortByColumn(0, 1)
+	fmt.Fprint(ctx.Stdout, tbl.String())
+	return nil
+}
+
+// planOptValue formats the value of a plan option, using JSON for nested
+// values when --wide is set.
+func (c *VolumePlansList) planOptValue(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		if c.wide {
+			if data, err := json.Marshal(v); err == nil {
+				return string(data)
+			}
+		}
+	}
+	return planOptString(v)
+}
+
+type VolumePlanShow struct {
+	fs      *gnuflag.FlagSet
+	json    bool
+	yaml    bool
+	retries int
+	conn    volumeConn
+}
+
+func (c *VolumePlanShow) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-plan-show",
+		Usage: "volume plan show <plan> [--json|--yaml]",
+		Desc: `Shows the details of a volume plan, including every option it sets. When
+more than one provisioner offers the plan, each of them is displayed.`,
+		MinArgs: 1,
+		MaxArgs: 1,
+	}
+}
+
+func (c *VolumePlanShow) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("volume-plan-show", gnuflag.ExitOnError)
+		c.fs.BoolVar(&c.json, "json", false, "Display in JSON format")
+		c.fs.BoolVar(&c.yaml, "yaml", false, "Display in YAML format")
+		c.fs.IntVar(&c.retries, "retries", 2, volumeRetriesDesc)
+		c.conn.addFlags(c.fs)
+	}
+	return c.fs
+}
+
+type volumePlanDetail struct {
+	Name        string
+	Provisioner string
+	Opts        map[string]interface{}
+}
+
+func (c *VolumePlanShow) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeError(ctx, &err, &c.json)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	if c.json && c.yaml {
+		return errors.New("the --json and --yaml flags are mutually exclusive")
+	}
+	planName := ctx.Args[0]
+	client = volumeReadClient(ctx, client, c.retries)
+	plans, err := c.conn.listVolumePlans(client)
+	if err != nil {
+		return err
+	}
+	var details []volumePlanDetail
+	names := map[string]struct{}{}
+	for provisioner, provPlans := range plans {
+		for _, p := range provPlans {
+			names[p.Name] = struct{}{}
+			if p.Name == planName {
+				details = append(details, volumePlanDetail{Name: p.Name, Provisioner: provisioner, Opts: p.Opts})
+			}
+		}
+	}
+	if len(details) == 0 {
+		available := make([]string, 0, len(names))
+		for name := range names {
+			available = append(available, name)
+		}
+		sort.Strings(available)
+		return fmt.Errorf("volume plan %q not found, available plans are: %s", planName, strings.Join(available, ", "))
+	}
+	sort.Slice(details, func(i, j int) bool {
+		return details[i].Provisioner < details[j].Provisioner
+	})
+	if c.json {
+		return formatter.JSON(ctx.Stdout, details)
+	}
+	if c.yaml {
+		data, err := yaml.Marshal(details)
+		if err != nil {
+			return err
+		}
+		_, err = ctx.Stdout.Write(data)
+		return err
+	}
+	for i, d := range details {
+		if i > 0 {
+			fmt.Fprintln(ctx.Stdout)
+		}
+		fmt.Fprintf(ctx.Stdout, "Name: %s\n", d.Name)
+		fmt.Fprintf(ctx.Stdout, "Provisioner: %s\n", d.Provisioner)
+		if len(d.Opts) == 0 {
+			continue
+		}
+		fmt.Fprintln(ctx.Stdout)
+		fmt.Fprintln(ctx.Stdout, "Opts:")
+		optsTable := tablecli.NewTable()
+		optsTable.Headers = []string{"Key", "Value"}
+		optsTable.LineSeparator = true
+		for k, v := range d.Opts {
+			optsTable.AddRow([]string{k, formatOptValue(v)})
+		}
+		optsTable.Sort()
+		fmt.Fprint(ctx.Stdout, optsTable.String())
+	}
+	return nil
+}
+
+type VolumeDelete struct {
+	cmd.ConfirmationCommand
+	fs       *gnuflag.FlagSet
+	force    bool
+	byFilter bool
+	filter   volumeFilter
+	quiet    bool
+	conn     volumeConn
+}
+
+func (c *VolumeDelete) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-delete",
+		Usage: "volume delete [volume-name]... [-y/--assume-yes] [--force] [--by-filter] [--pool <pool>] [--team <team>] [--plan <plan>] [--name-regex <regex>]",
+		Desc: `Delete one or more existing persistent volumes.
+
+A volume that is still bound to applications can only be deleted with the
+[[--force]] flag, which unbinds it from every application before deleting it.
+
+When several volumes are given, a failure deleting one of them does not stop
+the others from being deleted.
+
+With [[--by-filter]], no volume names are given: every volume matching the
+[[--pool]], [[--team]], [[--plan]] and [[--name-regex]] filters is deleted.
+At least one filter and [[--assume-yes]] are required in this mode.`,
+		MinArgs: 0,
+	}
+}
+
+func (c *VolumeDelete) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		fs := gnuflag.NewFlagSet("volume-delete", gnuflag.ExitOnError)
+		fs.BoolVar(&c.force, "force", false, "unbind the volume from all applications before deleting it")
+		fs.BoolVar(&c.byFilter, "by-filter", false, "delete every volume matching the given filters")
+		fs.StringVar(&c.filter.pool, "pool", "", "with --by-filter, delete volumes in this pool")
+		fs.StringVar(&c.filter.teamOwner, "team", "", "with --by-filter, delete volumes owned by this team")
+		fs.StringVar(&c.filter.plan, "plan", "", "with --by-filter, delete volumes using this plan")
+		fs.StringVar(&c.filter.nameRegex, "name-regex", "", "with --by-filter, delete volumes whose name matches this regular expression")
+		fs.BoolVar(&c.quiet, "quiet", false, volumeQuietDesc)
+		fs.BoolVar(&c.quiet, "q", false, volumeQuietDesc)
+		c.fs = cmd.MergeFlagSet(c.ConfirmationCommand.Flags(), fs)
+		c.conn.addFlags(c.fs)
+	}
+	return c.fs
+}
+
+func (c *VolumeDelete) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeExitCode(&err)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	if c.byFilter {
+		return c.runByFilter(ctx, client)
+	}
+	if len(ctx.Args) == 0 {
+		return errors.New("at least one volume name is required, or use --by-filter")
+	}
+	if len(ctx.Args) == 1 {
+		deleted, err := c.deleteVolume(ctx, client, ctx.Args[0])
+		if err != nil {
+			return err
+		}
+		if deleted && !c.quiet {
+			fmt.Fprint(ctx.Stdout, "Volume successfully deleted.\n")
+		}
+		return nil
+	}
+	return c.deleteVolumes(ctx, client, ctx.Args)
+}
+
+func (c *VolumeDelete) runByFilter(ctx *cmd.Context, client *cmd.Client) error {
+	if len(ctx.Args) > 0 {
+		return errors.New("volume names cannot be used together with --by-filter")
+	}
+	if c.filter.isEmpty() {
+		return errors.New("at least one filter is required when using --by-filter")
+	}
+	if !assumeYes(c.Flags()) {
+		return errors.New("--assume-yes is required when using --by-filter")
+	}
+	if err := c.filter.validate(); err != nil {
+		return err
+	}
+	qs, err := c.filter.queryString()
+	if err != nil {
+		return err
+	}
+	volumes, err := c.conn.listVolumes(client, qs)
+	if err != nil {
+		return err
+	}
+	volumes = c.filter.apply(volumes)
+	if len(volumes) == 0 {
+		fmt.Fprintln(ctx.Stdout, "No volumes match the given filters.")
+		return nil
+	}
+	volumeNames := make([]string, len(volumes))
+	for i, v := range volumes {
+		volumeNames[i] = v.Name
+	}
+	sort.Strings(volumeNames)
+	fmt.Fprintln(ctx.Stdout, "The following volumes will be deleted:")
+	for _, name := range volumeNames {
+		fmt.Fprintf(ctx.Stdout, " - %s\n", name)
+	}
+	return c.deleteVolumes(ctx, client, volumeNames)
+}
+
+func (c *VolumeDelete) deleteVolumes(ctx *cmd.Context, client *cmd.Client, volumeNames []string) error {
+	var failures int
+	for _, volumeName := range volumeNames {
+		deleted, err := c.deleteVolume(ctx, client, volumeName)
+		if err != nil {
+			failures++
+			fmt.Fprintf(ctx.Stderr, "Failed to delete volume %q: %v\n", volumeName, err)
+			continue
+		}
+		if deleted && !c.quiet {
+			fmt.Fprintf(ctx.Stdout, "Volume %q successfully deleted.\n", volumeName)
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("failed to delete %d of %d volumes", failures, len(volumeNames))
+	}
+	return nil
+}
+
+func (c *VolumeDelete) deleteVolume(ctx *cmd.Context, client *cmd.Client, volumeName string) (bool, error) {
+	// The volume is only needed to show its binds in the question and to
+	// unbind it with --force.
+	var volume *volumeData
+	var err error
+	if c.force || !assumeYes(c.Flags()) {
+		volume, err = c.conn.getVolume(client, volumeName)
+		if err != nil {
+			return false, err
+		}
+	}
+	question := fmt.Sprintf("Are you sure you want to delete volume %q?", volumeName)
+	if volume != nil && len(volume.Binds) > 0 {
+		question = fmt.Sprintf("Are you sure you want to delete volume %q (bound %d time(s))?", volumeName, len(volume.Binds))
+	}
+	if !c.Confirm(ctx, question) {
+		return false, nil
+	}
+	if c.force && volume != nil {
+		ctx.RawOutput()
+		for _, b := range volume.Binds {
+			fmt.Fprintf(ctx.Stdout, "Unbinding volume %q from app %q at %q...\n", volumeName, b.ID.App, b.ID.MountPoint)
+			err = (&VolumeUnbind{conn: c.conn}).unbind(ctx, client, volumeName, b.ID.App, b.ID.MountPoint)
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+	err = c.conn.removeVolume(client, volumeName)
+	if err != nil {
+		if volume == nil && !c.force {
+			// Only used to explain the failure, a lookup error is ignored.
+			volume, _ = c.conn.getVolume(client, volumeName)
+		}
+		if !c.force && volume != nil && len(volume.Binds) > 0 {
+			return false, fmt.Errorf("volume %q is still bound to %d app(s), use --force to unbind it before deleting: %w", volumeName, len(volume.Binds), err)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (t *volumeConn) removeVolume(client *cmd.Client, volumeName string) error {
+	u, err := t.volumeURL("/volumes/" + volumeName)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return err
+	}
+	_, err = client.Do(request)
+	return err
+}
+
+type VolumeBind struct {
+	cmd.AppNameMixIn
+	fs            *gnuflag.FlagSet
+	readOnly      bool
+	mode          string
+	noRestart     bool
+	strategy      string
+	subPath       string
+	propagation   string
+	timeout       time.Duration
+	dryRun        bool
+	wait          bool
+	waitTimeout   time.Duration
+	plain         bool
+	quiet         bool
+	skipPathCheck bool
+	ifNotBound    bool
+	conn          volumeConn
+}
+
+func (c *VolumeBind) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-bind",
+		Usage: "volume bind <volume-name> <mount point> [-a/--app <appname>[,<appname>]...] [-r/--readonly|--mode ro|rw] [--no-restart|--restart-strategy <strategy>] [--subpath <path>] [--propagation None|HostToContainer|Bidirectional] [--timeout <duration>] [--wait [--wait-timeout <duration>]] [--if-not-bound] [--plain] [--dry-run]",
+		Desc: `Binds an existing volume to an application.
+
+Several applications may be given to [[--app]] as a comma-separated list, in
+which case the volume is bound to each one of them at the same mount point.
+
+With [[--restart-strategy]], the strategy used to restart the application,
+such as rolling or immediate, is sent to the server. Strategies not supported
+by the server are rejected by it.
+
+When the volume.noRestart setting is true in ~/.tsuru/config.json, the
+application isn't restarted unless [[--no-restart=false]] or
+[[--restart-strategy]] is given, and a notice is displayed.
+
+With [[--propagation]], the mount propagation mode of the bind, None,
+HostToContainer or Bidirectional, is sent to the server. It's only honored by
+provisioners supporting it, such as Kubernetes, and omitted by default.
+
+With [[--wait]], the volume is polled after the bind request is accepted until
+the new bind shows up, so the mount is known to be active when the command
+returns.
+
+With [[--plain]], the progress events sent by the server are not displayed,
+only a line when each bind starts and the result.
+
+With [[--if-not-bound]], the binds of the volume are checked first and apps
+already bound to it at the same mount point are skipped, which makes the
+command safe to run repeatedly in scripts.
+
+With [[--dry-run]], the binds that would be made are printed and nothing is
+sent to the server.`,
+		MinArgs: 2,
+		MaxArgs: 2,
+	}
+}
+
+func (c *VolumeBind) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = c.AppNameMixIn.Flags()
+		desc := "the volume will be available only for reading"
+		c.fs.BoolVar(&c.readOnly, "readonly", false, desc)
+		c.fs.BoolVar(&c.readOnly, "r", false, desc)
+		c.fs.StringVar(&c.mode, "mode", "", "the access mode of the bind, ro (read-only) or rw (read-write, the default)")
+		c.fs.BoolVar(&c.noRestart, "no-restart", false, "prevents restarting the application")
+		c.fs.StringVar(&c.strategy, "restart-strategy", "", "the strategy used to restart the application, if supported by the server")
+		c.fs.StringVar(&c.subPath, "subpath", "", "mount only this path inside the volume")
+		c.fs.StringVar(&c.propagation, "propagation", "", "the mount propagation mode of the bind (None, HostToContainer or Bidirectional), if supported by the provisioner")
+		c.fs.DurationVar(&c.timeout, "timeout", 0, "abort if the bind takes longer than this duration (e.g. 5m)")
+		c.fs.BoolVar(&c.dryRun, "dry-run", false, "print the binds that would be made without making them")
+		c.fs.BoolVar(&c.wait, "wait", false, "wait until the bind is present in the volume")
+		c.fs.BoolVar(&c.plain, "plain", false, volumePlainDesc)
+		c.fs.DurationVar(&c.waitTimeout, "wait-timeout", 5*time.Minute, "with --wait, give up after this duration (e.g. 10m)")
+		c.fs.BoolVar(&c.skipPathCheck, "skip-path-check", false, "don't require the mount point to be an absolute path")
+		c.fs.BoolVar(&c.ifNotBound, "if-not-bound", false, "skip the apps already bound to the volume at the mount point")
+		c.fs.BoolVar(&c.quiet, "quiet", false, volumeQuietDesc)
+		c.fs.BoolVar(&c.quiet, "q", false, volumeQuietDesc)
+		c.conn.addFlags(c.fs)
+		c.conn.addBodyFlag(c.fs)
+	}
+	return c.fs
+}
+
+// volumeBindPropagations are the mount propagation modes supported by
+// Kubernetes.
+var volumeBindPropagations = []string{"None", "HostToContainer", "Bidirectional"}
+
+func (c *VolumeBind) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeExitCode(&err)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	ctx.RawOutput()
+	volumeName := ctx.Args[0]
+	if err := c.applyMode(); err != nil {
+		return err
+	}
+	if c.strategy == "" {
+		configNoRestart(ctx.Stderr, c.fs, &c.noRestart)
+	}
+	if c.noRestart && c.strategy != "" {
+		return errors.New("the --no-restart and --restart-strategy flags are conflicting")
+	}
+	if c.propagation != "" && !containsString(volumeBindPropagations, c.propagation) {
+		return fmt.Errorf("invalid propagation mode %q, valid options are: %s", c.propagation, strings.Join(volumeBindPropagations, ", "))
+	}
+	mountPoint := normalizeMountPoint(ctx.Stderr, ctx.Args[1])
+	if !c.skipPathCheck {
+		if err := checkMountPoint(mountPoint); err != nil {
+			return err
+		}
+	}
+	appNames, err := volumeAppNames(&c.AppNameMixIn)
+	if err != nil {
+		return err
+	}
+	if c.ifNotBound {
+		appNames, err = c.unboundApps(ctx, client, volumeName, mountPoint, appNames)
+		if err != nil || len(appNames) == 0 {
+			return err
+		}
+	}
+	if len(appNames) == 1 {
+		if c.plain && !c.dryRun && !c.quiet {
+			fmt.Fprintf(ctx.Stdout, "Binding volume %q to app %q at %q...\n", volumeName, appNames[0], mountPoint)
+		}
+		err = c.bind(ctx, client, volumeName, appNames[0], mountPoint)
+		if err != nil || c.dryRun {
+			return err
+		}
+		if c.wait {
+			if err = c.conn.waitVolumeBound(ctx, client, volumeName, mountPoint, appNames, c.waitTimeout); err != nil {
+				return err
+			}
+		}
+		if c.quiet {
+			return nil
+		}
+		fmt.Fprint(ctx.Stdout, "Volume successfully bound.\n")
+		fmt.Fprintln(ctx.Stdout, c.bindSummary(mountPoint, appNames))
+		return nil
+	}
+	var bound int
+	var failures []string
+	for _, appName := range appNames {
+		if !c.dryRun && !c.quiet {
+			fmt.Fprintf(ctx.Stdout, "Binding volume %q to app %q...\n", volumeName, appName)
+		}
+		err = c.bind(ctx, client, volumeName, appName, mountPoint)
+		if err != nil {
+			failures = append(failures, appName)
+			fmt.Fprintf(ctx.Stderr, "Failed to bind volume %q to app %q: %v\n", volumeName, appName, err)
+			continue
+		}
+		bound++
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to bind volume %q to app(s): %s", volumeName, strings.Join(failures, ", "))
+	}
+	if c.dryRun {
+		return nil
+	}
+	if c.wait {
+		if err = c.conn.waitVolumeBound(ctx, client, volumeName, mountPoint, appNames, c.waitTimeout); err != nil {
+			return err
+		}
+	}
+	if c.quiet {
+		return nil
+	}
+	fmt.Fprintf(ctx.Stdout, "Volume successfully bound to %d apps.\n", bound)
+	fmt.Fprintln(ctx.Stdout, c.bindSummary(mountPoint, appNames))
+	return nil
+}
+
+// waitVolumeBound polls the volume until it has a bind at mountPoint for
+// each one of apps, giving up after timeout when it's greater than zero.
+func (t *volumeConn) waitVolumeBound(ctx *cmd.Context, client *cmd.Client, volumeName, mountPoint string, apps []string, timeout time.Duration) error {
+	fmt.Fprintf(ctx.Stderr, "Waiting for volume %q to be mounted at %q", volumeName, mountPoint)
+	defer fmt.Fprintln(ctx.Stderr)
+	deadline := time.Now().Add(timeout)
+	for {
+		volume, err := t.getVolume(client, volumeName)
+		if err != nil {
+			return err
+		}
+		if volume == nil {
+			return fmt.Errorf("volume %q not found", volumeName)
+		}
+		bound := map[string]bool{}
+		for _, b := range volume.Binds {
+			if b.ID.MountPoint == mountPoint {
+				bound[b.ID.App] = true
+			}
+		}
+		var missing []string
+		for _, app := range apps {
+			if !bound[app] {
+				missing = append(missing, app)
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+		if timeout > 0 && time.Now().Add(volumeWaitInterval).After(deadline) {
+			return fmt.Errorf("timed out waiting for volume %q to be mounted at %q after %s, bind not present for app(s): %s", volumeName, mountPoint, timeout, strings.Join(missing, ", "))
+		}
+		fmt.Fprint(ctx.Stderr, ".")
+		time.Sleep(volumeWaitInterval)
+	}
+}
+
+// bindSummary tells which mount point is now active in apps and whether they
+// were restarted, which only doesn't happen with --no-restart.
+func (c *VolumeBind) bindSummary(mountPoint string, apps []string) string {
+	target := fmt.Sprintf("app %q", apps[0])
+	restart := "app restarted"
+	if len(apps) > 1 {
+		target = "apps " + strings.Join(apps, ", ")
+		restart = "apps restarted"
+	}
+	if c.noRestart {
+		restart = "restart skipped with --no-restart"
+	} else if c.strategy != "" {
+		restart += " with the " + c.strategy + " strategy"
+	}
+	return fmt.Sprintf("Mount point %q is now active in %s (%s).", mountPoint, target, restart)
+}
+
+// volumeAppNames returns the apps given to the --app flag, which may be a
+// comma-separated list. Blank entries are ignored, and an error is returned
+// when no app is left so that binds are never sent without an app.
+func volumeAppNames(mixin *cmd.AppNameMixIn) ([]string, error) {
+	appName, err := mixin.AppName()
+	if err != nil {
+		return nil, err
+	}
+	var appNames []string
+	for _, name := range strings.Split(appName, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			appNames = append(appNames, name)
+		}
+	}
+	if len(appNames) == 0 {
+		return nil, fmt.Errorf("invalid app name %q.\n\nUse the --app flag to specify the name of the app, e.g. --app myapp", appName)
+	}
+	return appNames, nil
+}
+
+// applyMode sets the bind as read-only according to --mode, which must not
+// contradict the -r/--readonly flag.
+func (c *VolumeBind) applyMode() error {
+	switch c.mode {
+	case "":
+	case "ro":
+		c.readOnly = true
+	case "rw":
+		if c.readOnly {
+			return errors.New("the --mode rw and -r/--readonly flags are conflicting")
+		}
+	default:
+		return fmt.Errorf("invalid mode %q, valid options are: ro, rw", c.mode)
+	}
+	return nil
+}
+
+// unboundApps returns the apps in appNames that aren't bound to volumeName
+// at exactly mountPoint, reporting the ones that are.
+func (c *VolumeBind) unboundApps(ctx *cmd.Context, client *cmd.Client, volumeName, mountPoint string, appNames []string) ([]string, error) {
+	volume, err := c.conn.getVolume(client, volumeName)
+	if err != nil {
+		return nil, err
+	}
+	if volume == nil {
+		return nil, fmt.Errorf("volume %q not found", volumeName)
+	}
+	bound := map[string]bool{}
+	for _, b := range volume.Binds {
+		if b.ID.MountPoint == mountPoint {
+			bound[b.ID.App] = true
+		}
+	}
+	var unbound []string
+	for _, appName := range appNames {
+		if !bound[appName] {
+			unbound = append(unbound, appName)
+			continue
+		}
+		if !c.quiet {
+			fmt.Fprintf(ctx.Stdout, "Volume %q is already bound to app %q at %q.\n", volumeName, appName, mountPoint)
+		}
+	}
+	return unbound, nil
+}
+
+// normalizeMountPoint returns mountPoint without trailing or duplicated
+// slashes, writing a warning to w when that changes it.
+func normalizeMountPoint(w io.Writer, mountPoint string) string {
+	if mountPoint == "" {
+		return mountPoint
+	}
+	cleaned := path.Clean(mountPoint)
+	if cleaned != mountPoint {
+		fmt.Fprintf(w, "Warning: using mount point %q instead of %q.\n", cleaned, mountPoint)
+	}
+	return cleaned
+}
+
+// checkMountPoint returns an error when mountPoint is not an absolute path.
+func checkMountPoint(mountPoint string) error {
+	if strings.HasPrefix(mountPoint, "/") {
+		return nil
+	}
+	return fmt.Errorf("invalid mount point %q: it must be an absolute path such as %q (use --skip-path-check to send it anyway)", mountPoint, "/"+mountPoint)
+}
+
+func (c *VolumeBind) bind(ctx *cmd.Context, client *cmd.Client, volumeName, appName, mountPoint string) error {
+	bind := struct {
+		App             string
+		MountPoint      string
+		ReadOnly        bool
+		NoRestart       bool
+		RestartStrategy string `form:",omitempty" json:",omitempty"`
+		SubPath         string `form:",omitempty" json:",omitempty"`
+		Propagation     string `form:",omitempty" json:",omitempty"`
+	}{
+		App:             appName,
+		MountPoint:      mountPoint,
+		ReadOnly:        c.readOnly,
+		NoRestart:       c.noRestart,
+		RestartStrategy: c.strategy,
+		SubPath:         c.subPath,
+		Propagation:     c.propagation,
+	}
+	body, contentType, err := encodeVolumeBody(bind, c.conn.jsonBody)
+	if err != nil {
+		return err
+	}
+	u, err := c.conn.volumeURL(fmt.Sprintf("/volumes/%s/bind", volumeName))
+	if err != nil {
+		return err
+	}
+	if c.dryRun {
+		fmt.Fprintf(ctx.Stdout, "Would bind volume %q to app %q:\n  POST %s\n", volumeName, appName, u)
+		fmt.Fprintf(ctx.Stdout, "  App: %s\n  MountPoint: %s\n  ReadOnly: %t\n  NoRestart: %t\n", bind.App, bind.MountPoint, bind.ReadOnly, bind.NoRestart)
+		if bind.RestartStrategy != "" {
+			fmt.Fprintf(ctx.Stdout, "  RestartStrategy: %s\n", bind.RestartStrategy)
+		}
+		if bind.SubPath != "" {
+			fmt.Fprintf(ctx.Stdout, "  SubPath: %s\n", bind.SubPath)
+		}
+		if bind.Propagation != "" {
+			fmt.Fprintf(ctx.Stdout, "  Propagation: %s\n", bind.Propagation)
+		}
+		return nil
+	}
+	request, err := http.NewRequest("POST", u, body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", contentType)
+	return streamVolumeRequest(volumeStreamOutput(ctx, c.plain), client, request, c.timeout)
+}
+
+type VolumeUnbind struct {
+	cmd.AppNameMixIn
+	cmd.ConfirmationCommand
+	fs        *gnuflag.FlagSet
+	noRestart bool
+	all       bool
+	allApps   bool
+	ifBound   bool
+	timeout   time.Duration
+	plain     bool
+	quiet     bool
+	conn      volumeConn
+}
+
+func (c *VolumeUnbind) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-unbind",
+		Usage: "volume unbind <volume-name> [mount point] [-a/--app <appname>] [--all|--all-apps] [--if-bound] [--no-restart] [--timeout <duration>] [--plain] [-y/--assume-yes]",
+		Desc: `Unbinds a volume from an application.
+
+With [[--all]], every bind of the volume is removed. The mount point and the
+[[--app]] flag become optional and, when given, restrict which binds are
+removed.
+
+With [[--all-apps]], the volume is unbound from every application using the
+given mount point. A failure to unbind one application doesn't stop the
+others from being unbound, and the applications that failed are reported at
+the end.
+
+With [[--if-bound]], the binds of the volume are checked first and nothing is
+done when the application isn't bound to it at the given mount point, which
+makes the command safe to run repeatedly in scripts. The [[--all]] and
+[[--all-apps]] flags never fail when there are no binds to remove.
+
+Unbinding a volume restarts the application, so a confirmation is asked for
+each bind unless [[--no-restart]] or [[--assume-yes]] is used. When the
+volume.noRestart setting is true in ~/.tsuru/config.json, the application
+isn't restarted unless [[--no-restart=false]] is given, and a notice is
+displayed.
+
+With [[--plain]], the progress events sent by the server are not displayed,
+only a line when each unbind starts and the result.`,
+		MinArgs: 1,
+		MaxArgs: 2,
+	}
+}
+
+func (c *VolumeUnbind) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = cmd.MergeFlagSet(c.AppNameMixIn.Flags(), c.ConfirmationCommand.Flags())
+		c.fs.BoolVar(&c.noRestart, "no-restart", false, "prevents restarting the application")
+		c.fs.BoolVar(&c.all, "all", false, "unbind the volume from every application")
+		c.fs.BoolVar(&c.allApps, "all-apps", false, "unbind the volume from every application using the given mount point")
+		c.fs.BoolVar(&c.ifBound, "if-bound", false, "do nothing when the application isn't bound to the volume at the mount point")
+		c.fs.DurationVar(&c.timeout, "timeout", 0, "abort if the unbind takes longer than this duration (e.g. 5m)")
+		c.fs.BoolVar(&c.plain, "plain", false, volumePlainDesc)
+		c.fs.BoolVar(&c.quiet, "quiet", false, volumeQuietDesc)
+		c.fs.BoolVar(&c.quiet, "q", false, volumeQuietDesc)
+		c.conn.addFlags(c.fs)
+	}
+	return c.fs
+}
+
+func (c *VolumeUnbind) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeExitCode(&err)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	ctx.RawOutput()
+	configNoRestart(ctx.Stderr, c.fs, &c.noRestart)
+	volumeName := ctx.Args[0]
+	if c.allApps {
+		if c.all {
+			return errors.New("the --all and --all-apps flags are mutually exclusive")
+		}
+		if len(ctx.Args) < 2 {
+			return errors.New("the mount point is required with --all-apps")
+		}
+		if c.Flags().Lookup("app").Value.String() != "" {
+			return errors.New("the --app flag can't be used with --all-apps")
+		}
+		return c.unbindAll(ctx, client, volumeName)
+	}
+	if c.all {
+		return c.unbindAll(ctx, client, volumeName)
+	}
+	if len(ctx.Args) < 2 {
+		return errors.New("the mount point is required unless --all is used")
+	}
+	appNames, err := volumeAppNames(&c.AppNameMixIn)
+	if err != nil {
+		return err
+	}
+	if len(appNames) > 1 {
+		return errors.New("only one app can be given to --app when unbinding a single mount point")
+	}
+	mountPoint := normalizeMountPoint(ctx.Stderr, ctx.Args[1])
+	// The volume is only looked up when it's needed, so users who can't
+	// read it are still able to unbind it.
+	if c.ifBound {
+		var found bool
+		mountPoint, found, err = c.conn.boundMountPoint(client, volumeName, appNames[0], mountPoint)
+		if err != nil {
+			return err
+		}
+		if !found {
+			if !c.quiet {
+				fmt.Fprintf(ctx.Stdout, "Volume %q is not bound to app %q at %q.\n", volumeName, appNames[0], mountPoint)
+			}
+			return nil
+		}
+	}
+	if !c.confirmRestart(ctx, volumeName, appNames[0], mountPoint) {
+		return nil
+	}
+	if !c.ifBound && mountPoint != ctx.Args[1] {
+		mountPoint, _, err = c.conn.boundMountPoint(client, volumeName, appNames[0], mountPoint)
+		if err != nil {
+			return err
+		}
+	}
+	if c.plain && !c.quiet {
+		fmt.Fprintf(ctx.Stdout, "Unbinding volume %q from app %q at %q...\n", volumeName, appNames[0], mountPoint)
+	}
+	err = c.unbind(ctx, client, volumeName, appNames[0], mountPoint)
+	if err != nil || c.quiet {
+		return err
+	}
+	fmt.Fprint(ctx.Stdout, "Volume successfully unbound.\n")
+	return nil
+}
+
+func (c *VolumeUnbind) unbindAll(ctx *cmd.Context, client *cmd.Client, volumeName string) error {
+	var mountPoint string
+	if len(ctx.Args) > 1 {
+		mountPoint = normalizeMountPoint(ctx.Stderr, ctx.Args[1])
+	}
+	appName := c.Flags().Lookup("app").Value.String()
+	volume, err := c.conn.getVolume(client, volumeName)
+	if err != nil {
+		return err
+	}
+	if volume == nil {
+		return fmt.Errorf("volume %q not found", volumeName)
+	}
+	var unbound, declined int
+	var failures []string
+	for _, b := range volume.Binds {
+		if appName != "" && b.ID.App != appName {
+			continue
+		}
+		if mountPoint != "" && path.Clean(b.ID.MountPoint) != mountPoint {
+			continue
+		}
+		if !c.confirmRestart(ctx, volumeName, b.ID.App, b.ID.MountPoint) {
+			declined++
+			continue
+		}
+		if !c.quiet {
+			fmt.Fprintf(ctx.Stdout, "Unbinding volume %q from app %q at %q...\n", volumeName, b.ID.App, b.ID.MountPoint)
+		}
+		err = c.unbind(ctx, client, volumeName, b.ID.App, b.ID.MountPoint)
+		if err != nil {
+			if !c.allApps {
+				return err
+			}
+			failures = append(failures, b.ID.App)
+			fmt.Fprintf(ctx.Stderr, "Failed to unbind volume %q from app %q: %v\n", volumeName, b.ID.App, err)
+			continue
+		}
+		unbound++
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to unbind volume %q from app(s): %s", volumeName, strings.Join(failures, ", "))
+	}
+	if unbound == 0 {
+		if declined == 0 && !c.quiet {
+			fmt.Fprintln(ctx.Stdout, "No binds to remove.")
+		}
+		return nil
+	}
+	if !c.quiet {
+		fmt.Fprintf(ctx.Stdout, "Volume successfully unbound from %d bind(s).\n", unbound)
+	}
+	return nil
+}
+
+// boundMountPoint returns the mount point of the bind of volumeName to
+// appName matching mountPoint once both are normalized, as the server expects
+// it exactly as it was stored, and whether such a bind exists. mountPoint is
+// returned as is when no bind matches, leaving it to the server to report the
+// error.
+func (t *volumeConn) boundMountPoint(client *cmd.Client, volumeName, appName, mountPoint string) (string, bool, error) {
+	volume, err := t.getVolume(client, volumeName)
+	if err != nil {
+		return "", false, err
+	}
+	if volume == nil {
+		return "", false, fmt.Errorf("volume %q not found", volumeName)
+	}
+	for _, b := range volume.Binds {
+		if b.ID.App == appName && path.Clean(b.ID.MountPoint) == mountPoint {
+			return b.ID.MountPoint, true, nil
+		}
+	}
+	return mountPoint, false, nil
+}
+
+// confirmRestart asks the user to confirm an unbind that restarts the app,
+// returning true right away when --no-restart is set.
+func (c *VolumeUnbind) confirmRestart(ctx *cmd.Context, volumeName, appName, mountPoint string) bool {
+	if c.noRestart {
+		return true
+	}
+	question := fmt.Sprintf("Unbinding volume %q from app %q at %q will restart the app. Are you sure you want to continue?", volumeName, appName, mountPoint)
+	return c.Confirm(ctx, question)
+}
+
+func (c *VolumeUnbind) unbind(ctx *cmd.Context, client *cmd.Client, volumeName, appName, mountPoint string) error {
+	bind := struct {
+		App        string
+		MountPoint string
+		NoRestart  bool
+	}{
+		App:        appName,
+		MountPoint: mountPoint,
+		NoRestart:  c.noRestart,
+	}
+	val, err := form.EncodeToValues(bind)
+	if err != nil {
+		return err
+	}
+	u, err := c.conn.volumeURL(fmt.Sprintf("/volumes/%s/bind?%s", volumeName, val.Encode()))
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return err
+	}
+	return streamVolumeRequest(volumeStreamOutput(ctx, c.plain), client, request, c.timeout)
+}
+
+const volumePlainDesc = "don't display the progress events sent by the server"
+
+// volumeStreamOutput returns where the progress events of a streamed
+// response are written, discarding them when plain is set. Errors in the
+// stream are still reported by streamVolumeRequest.
+func volumeStreamOutput(ctx *cmd.Context, plain bool) io.Writer {
+	if plain {
+		return io.Discard
+	}
+	return ctx.Stdout
+}
+
+// streamVolumeRequest sends request and streams its JSON response to w,
+// aborting when it takes longer than timeout. A zero timeout means no limit.
+func streamVolumeRequest(w io.Writer, client *cmd.Client, request *http.Request, timeout time.Duration) error {
+	if timeout > 0 {
+		reqCtx, cancel := context.WithTimeout(request.Context(), timeout)
+		defer cancel()
+		request = request.WithContext(reqCtx)
+	}
+	resp, err := client.Do(request)
+	if err == nil {
+		err = cmd.StreamJSONResponse(w, resp)
+	}
+	if err != nil && request.Context().Err() == context.DeadlineExceeded {
+		return fmt.Errorf("operation timed out after %s", timeout)
+	}
+	return err
+}
+
+// volumeConn holds the connection flags shared by the volume commands,
+// allowing them to talk to API servers using self-signed or privately issued
+// certificates. The settings only apply to the command being run and are
+// never persisted.
+type volumeConn struct {
+	insecure   bool
+	caFile     string
+	debug      bool
+	apiVersion string
+	proxy      string
+	jsonBody   bool
+}
+
+func (t *volumeConn) addFlags(fs *gnuflag.FlagSet) {
+	fs.BoolVar(&t.insecure, "insecure", false, "Skip verification of the API server TLS certificate")
+	fs.StringVar(&t.caFile, "ca-file", "", "Path to a PEM bundle with the CA certificates used to verify the API server")
+	fs.BoolVar(&t.debug, "debug", false, "Print the method, URL, headers and body of each request to stderr, with the Authorization header redacted")
+	fs.StringVar(&t.proxy, "proxy", "", "URL of the proxy used to reach the API server, overriding the HTTP_PROXY and HTTPS_PROXY environment variables")
+	fs.StringVar(&t.apiVersion, "api-version", "", "Advanced: API version used in the volume requests, for testing against newer servers (defaults to "+volumeDefaultAPIVersion+")")
+}
+
+// addBodyFlag adds the --json-body flag, only meaningful to the commands
+// sending a request body.
+func (t *volumeConn) addBodyFlag(fs *gnuflag.FlagSet) {
+	fs.BoolVar(&t.jsonBody, "json-body", false, "Advanced: send the request body as JSON instead of form encoded, for servers preferring it")
+}
+
+const volumeDefaultAPIVersion = "1.4"
+
+var volumeAPIVersionRegexp = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+
+// volumeURL returns the URL of path in the volume API, using the version
+// given in --api-version, if any.
+func (t *volumeConn) volumeURL(path string) (string, error) {
+	version := t.apiVersion
+	if version == "" {
+		version = volumeDefaultAPIVersion
+	}
+	return cmd.GetURLVersion(version, path)
+}
+
+// encodeVolumeBody encodes v as the body of a volume request, returning it
+// along with its content type. Bodies are form encoded unless asJSON is
+// set, as done by --json-body, in which case v is marshaled as JSON. Form
+// values are percent-encoded, so option values holding characters such as
+// "&", "=" or newlines reach the server unchanged.
+func encodeVolumeBody(v interface{}, asJSON bool) (io.Reader, string, error) {
+	if asJSON {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(data), "application/json", nil
+	}
+	val, err := form.EncodeToValues(v)
+	if err != nil {
+		return nil, "", err
+	}
+	return strings.NewReader(val.Encode()), "application/x-www-form-urlencoded", nil
+}
+
+// apply makes client honor the connection flags, after validating the
+// version given in --api-version. The HTTP client is replaced with one
+// honoring the proxy, TLS and --debug flags, leaving the shared HTTP client
+// and transport untouched.
+func (t *volumeConn) apply(ctx *cmd.Context, client *cmd.Client) error {
+	if t.apiVersion != "" && !volumeAPIVersionRegexp.MatchString(t.apiVersion) {
+		return fmt.Errorf("invalid API version %q, it must be like %s", t.apiVersion, volumeDefaultAPIVersion)
+	}
+	if client == nil {
+		// Commands failing their own validation never send requests and
+		// may be run without a client.
+		return nil
+	}
+	if err := t.applyProxy(client); err != nil {
+		return err
+	}
+	if err := t.applyTLS(client); err != nil {
+		return err
+	}
+	if t.debug {
+		httpClient := *client.HTTPClient
+		httpClient.Transport = &debugTransport{base: client.HTTPClient.Transport, w: ctx.Stderr}
+		client.HTTPClient = &httpClient
+	}
+	return nil
+}
+
+// applyProxy makes client send its requests through the proxy given in
+// --proxy or, when it's not set, through the one given in the HTTP_PROXY and
+// HTTPS_PROXY environment variables, honoring NO_PROXY. The shared transport
+// doesn't look at the environment by itself.
+func (t *volumeConn) applyProxy(client *cmd.Client) error {
+	proxy := http.ProxyFromEnvironment
+	if t.proxy != "" {
+		u, err := url.Parse(t.proxy)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			return fmt.Errorf("invalid proxy %q, it must be a URL like http://proxy.example.com:3128", t.proxy)
+		}
+		proxy = http.ProxyURL(u)
+	}
+	transport, err := cloneTransport(client.HTTPClient.Transport, func(base *http.Transport) {
+		base.Proxy = proxy
+	})
+	if err != nil {
+		if t.proxy == "" {
+			// Transports we don't know how to change are kept as is
+			// unless a proxy was explicitly requested.
+			return nil
+		}
+		return err
+	}
+	httpClient := *client.HTTPClient
+	httpClient.Transport = transport
+	client.HTTPClient = &httpClient
+	return nil
+}
+
+func (t *volumeConn) applyTLS(client *cmd.Client) error {
+	if !t.insecure && t.caFile == "" {
+		return nil
+	}
+	if t.insecure && t.caFile != "" {
+		return errors.New("the --insecure and --ca-file flags are mutually exclusive")
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: t.insecure}
+	if t.caFile != "" {
+		f, err := filesystem().Open(t.caFile)
+		if err != nil {
+			return fmt.Errorf("unable to read CA file: %w", err)
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return fmt.Errorf("unable to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates found in CA file %q", t.caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport, err := cloneTransport(client.HTTPClient.Transport, func(base *http.Transport) {
+		base.TLSClientConfig = tlsConfig
+	})
+	if err != nil {
+		return err
+	}
+	httpClient := *client.HTTPClient
+	httpClient.Transport = transport
+	client.HTTPClient = &httpClient
+	return nil
+}
+
+// debugTransport writes the method, URL, headers and body of each request to
+// w before sending it, redacting the Authorization header.
+type debugTransport struct {
+	base http.RoundTripper
+	w    io.Writer
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fmt.Fprintf(t.w, "DEBUG: %s %s\n", req.Method, req.URL)
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		value := strings.Join(req.Header[k], ", ")
+		if k == "Authorization" {
+			value = "<redacted>"
+		}
+		fmt.Fprintf(t.w, "DEBUG:   %s: %s\n", k, value)
+	}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			if len(data) > 0 {
+				fmt.Fprintf(t.w, "DEBUG:   Body: %s\n", data)
+			}
+		}
+	}
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// cloneTransport returns a copy of rt with the underlying *http.Transport
+// cloned and changed by configure, leaving rt untouched.
+func cloneTransport(rt http.RoundTripper, configure func(*http.Transport)) (http.RoundTripper, error) {
+	switch t := rt.(type) {
+	case nil:
+		base := http.DefaultTransport.(*http.Transport).Clone()
+		configure(base)
+		return base, nil
+	case *http.Transport:
+		base := t.Clone()
+		configure(base)
+		return base, nil
+	case *cmd.VerboseRoundTripper:
+		inner, err := cloneTransport(t.RoundTripper, configure)
+		if err != nil {
+			return nil, err
+		}
+		verbose := *t
+		verbose.RoundTripper = inner
+		return &verbose, nil
+	case *tsuruNet.AutoOpentracingTransport:
+		inner, err := cloneTransport(t.RoundTripper, configure)
+		if err != nil {
+			return nil, err
+		}
+		return &tsuruNet.AutoOpentracingTransport{RoundTripper: inner}, nil
+	}
+	return nil, fmt.Errorf("unable to configure HTTP transport %T", rt)
+}
+
+const volumeRetriesDesc = "Number of times to retry the request on server or connection errors"
+
+var volumeRetryBackoff = 500 * time.Millisecond
+
+// volumeReadClient returns a copy of client that retries failed GET requests
+// up to retries times, waiting a bit longer between each attempt. Only
+// server errors (5xx) and connection errors are retried. Requests with
+// other methods are never retried, so commands with side effects must not
+// rely on it.
+func volumeReadClient(ctx *cmd.Context, client *cmd.Client, retries int) *cmd.Client {
+	if retries <= 0 {
+		return client
+	}
+	base := client.HTTPClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	httpClient := *client.HTTPClient
+	httpClient.Transport = &retryTransport{
+		base:    base,
+		retries: retries,
+		stderr:  ctx.Stderr,
+	}
+	retryClient := *client
+	retryClient.HTTPClient = &httpClient
+	return &retryClient
+}
+
+type retryTransport struct {
+	base    http.RoundTripper
+	retries int
+	stderr  io.Writer
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if req.Method != http.MethodGet {
+		return resp, err
+	}
+	for attempt := 1; attempt <= t.retries; attempt++ {
+		// Requests that were cancelled or timed out are not retried.
+		if req.Context().Err() != nil {
+			return resp, err
+		}
+		var reason string
+		switch {
+		case err != nil:
+			reason = err.Error()
+		case resp.StatusCode >= 500:
+			reason = resp.Status
+		default:
+			return resp, nil
+		}
+		if err == nil {
+			resp.Body.Close()
+		}
+		wait := volumeRetryBackoff * time.Duration(1<<(attempt-1))
+		if t.stderr != nil {
+			fmt.Fprintf(t.stderr, "Request to %s failed (%s), retrying in %s (attempt %d of %d)...\n", req.URL.Path, reason, wait, attempt, t.retries)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		resp, err = t.base.RoundTrip(req)
+	}
+	return resp, err
+}
+
+type VolumeRename struct {
+	cmd.ConfirmationCommand
+	fs   *gnuflag.FlagSet
+	conn volumeConn
+}
+
+func (c *VolumeRename) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-rename",
+		Usage: "volume rename <old-name> <new-name> [-y/--assume-yes]",
+		Desc: `Renames a persistent volume.
+
+The volume is renamed by creating a new volume with the same plan, pool, team
+and options, moving each bind of the old volume to the new one and then
+removing the old volume. If moving a bind fails, the binds already moved are
+restored, the new volume is removed and the old one is left as it was.
+
+Note that the applications bound to the volume will be restarted once.`,
+		MinArgs: 2,
+		MaxArgs: 2,
+	}
+}
+
+func (c *VolumeRename) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		fs := gnuflag.NewFlagSet("volume-rename", gnuflag.ExitOnError)
+		c.conn.addFlags(fs)
+		c.fs = cmd.MergeFlagSet(c.ConfirmationCommand.Flags(), fs)
+	}
+	return c.fs
+}
+
+func (c *VolumeRename) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeExitCode(&err)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	ctx.RawOutput()
+	oldName, newName := ctx.Args[0], ctx.Args[1]
+	if newName == "" {
+		return errors.New("the new volume name can't be empty")
+	}
+	if newName == oldName {
+		return fmt.Errorf("volume %q is already named %q", oldName, newName)
+	}
+	volume, err := c.conn.getVolume(client, oldName)
+	if err != nil {
+		return err
+	}
+	if volume == nil {
+		return fmt.Errorf("volume %q not found", oldName)
+	}
+	if !c.Confirm(ctx, fmt.Sprintf("Are you sure you want to rename volume %q to %q?", oldName, newName)) {
+		return nil
+	}
+	fmt.Fprintf(ctx.Stdout, "Creating volume %q...\n", newName)
+	newVolume := volumeTypes.Volume{
+		Name:      newName,
+		Plan:      volumeTypes.VolumePlan{Name: volume.Plan.Name},
+		Pool:      volume.Pool,
+		TeamOwner: volume.TeamOwner,
+		Opts:      volume.Opts,
+	}
+	err = c.conn.createVolume(client, newVolume)
+	if err != nil {
+		return err
+	}
+	// The old volume is unbound before the new one is bound at the same mount
+	// point, which the server would otherwise reject. Only the last bind of
+	// each app restarts it.
+	lastBind := make(map[string]int)
+	for i, b := range volume.Binds {
+		lastBind[b.ID.App] = i
+	}
+	var moved []volumeBindData
+	for i, b := range volume.Binds {
+		fmt.Fprintf(ctx.Stdout, "Unbinding volume %q from app %q at %q...\n", oldName, b.ID.App, b.ID.MountPoint)
+		err = (&VolumeUnbind{conn: c.conn, noRestart: true}).unbind(ctx, client, oldName, b.ID.App, b.ID.MountPoint)
+		if err != nil {
+			fmt.Fprintf(ctx.Stdout, "Failed to unbind volume %q, rolling back...\n", oldName)
+			c.rollback(ctx, client, oldName, newName, moved)
+			return err
+		}
+		fmt.Fprintf(ctx.Stdout, "Binding volume %q to app %q at %q...\n", newName, b.ID.App, b.ID.MountPoint)
+		bind := &VolumeBind{conn: c.conn, noRestart: lastBind[b.ID.App] != i, readOnly: b.ReadOnly, subPath: b.SubPath, propagation: b.Propagation}
+		err = bind.bind(ctx, client, newName, b.ID.App, b.ID.MountPoint)
+		if err != nil {
+			fmt.Fprintf(ctx.Stdout, "Failed to bind volume %q, rolling back...\n", newName)
+			c.restoreBind(ctx, client, oldName, b)
+			c.rollback(ctx, client, oldName, newName, moved)
+			return err
+		}
+		moved = append(moved, b)
+	}
+	fmt.Fprintf(ctx.Stdout, "Removing volume %q...\n", oldName)
+	err = c.conn.removeVolume(client, oldName)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Stdout, "Volume %q successfully renamed to %q.\n", oldName, newName)
+	return nil
+}
+
+// rollback moves the binds already moved to newName back to oldName, in
+// reverse order, and then removes newName.
+func (c *VolumeRename) rollback(ctx *cmd.Context, client *cmd.Client, oldName, newName string, moved []volumeBindData) {
+	for i := len(moved) - 1; i >= 0; i-- {
+		b := moved[i]
+		err := (&VolumeUnbind{conn: c.conn, noRestart: true}).unbind(ctx, client, newName, b.ID.App, b.ID.MountPoint)
+		if err != nil {
+			fmt.Fprintf(ctx.Stderr, "Failed to unbind volume %q from app %q: %v\n", newName, b.ID.App, err)
+			continue
+		}
+		c.restoreBind(ctx, client, oldName, b)
+	}
+	err := c.conn.removeVolume(client, newName)
+	if err != nil {
+		fmt.Fprintf(ctx.Stderr, "Failed to remove volume %q: %v\n", newName, err)
+	}
+}
+
+// restoreBind binds oldName back to the app and mount point of b, with the
+// same options it had.
+func (c *VolumeRename) restoreBind(ctx *cmd.Context, client *cmd.Client, oldName string, b volumeBindData) {
+	bind := &VolumeBind{conn: c.conn, readOnly: b.ReadOnly, subPath: b.SubPath, propagation: b.Propagation}
+	err := bind.bind(ctx, client, oldName, b.ID.App, b.ID.MountPoint)
+	if err != nil {
+		fmt.Fprintf(ctx.Stderr, "Failed to bind volume %q back to app %q at %q: %v\n", oldName, b.ID.App, b.ID.MountPoint, err)
+	}
+}
+
+type VolumeClone struct {
+	fs        *gnuflag.FlagSet
+	pool      string
+	team      string
+	opt       cmd.MapFlag
+	withBinds bool
+	conn      volumeConn
+}
+
+func (c *VolumeClone) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-clone",
+		Usage: "volume clone <source-volume> <new-volume> [-p/--pool <pool>] [-t/--team <team>] [-o/--opt key=value]... [--with-binds]",
+		Desc: `Creates a new persistent volume with the same plan, pool, team and options
+of an existing volume. The pool, team and options can be overridden with
+flags.
+
+Binds are not copied unless [[--with-binds]] is given.`,
+		MinArgs: 2,
+		MaxArgs: 2,
+	}
+}
+
+func (c *VolumeClone) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("volume-clone", gnuflag.ExitOnError)
+		desc := "the pool that owns the new volume (defaults to the source volume pool)"
+		c.fs.StringVar(&c.pool, "pool", "", desc)
+		c.fs.StringVar(&c.pool, "p", "", desc)
+		desc = "the team that owns the new volume (defaults to the source volume team)"
+		c.fs.StringVar(&c.team, "team", "", desc)
+		c.fs.StringVar(&c.team, "t", "", desc)
+		desc = "backend specific volume options, overriding the source volume options"
+		c.fs.Var(&c.opt, "opt", desc)
+		c.fs.Var(&c.opt, "o", desc)
+		c.fs.BoolVar(&c.withBinds, "with-binds", false, "bind the new volume to the same applications as the source volume")
+		c.conn.addFlags(c.fs)
+	}
+	return c.fs
+}
+
+func (c *VolumeClone) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeExitCode(&err)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	ctx.RawOutput()
+	sourceName, newName := ctx.Args[0], ctx.Args[1]
+	source, err := c.conn.getVolume(client, sourceName)
+	if err != nil {
+		return err
+	}
+	if source == nil {
+		return fmt.Errorf("volume %q not found", sourceName)
+	}
+	newVolume := volumeTypes.Volume{
+		Name:      newName,
+		Plan:      volumeTypes.VolumePlan{Name: source.Plan.Name},
+		Pool:      source.Pool,
+		TeamOwner: source.TeamOwner,
+		Opts:      map[string]string{},
+	}
+	if c.pool != "" {
+		newVolume.Pool = c.pool
+	}
+	if c.team != "" {
+		newVolume.TeamOwner = c.team
+	}
+	for k, v := range source.Opts {
+		newVolume.Opts[k] = v
+	}
+	for k, v := range c.opt {
+		newVolume.Opts[k] = v
+	}
+	err = c.conn.createVolume(client, newVolume)
+	if err != nil {
+		return err
+	}
+	if c.withBinds {
+		for _, b := range source.Binds {
+			fmt.Fprintf(ctx.Stdout, "Binding volume %q to app %q at %q...\n", newName, b.ID.App, b.ID.MountPoint)
+			bind := &VolumeBind{conn: c.conn, readOnly: b.ReadOnly, subPath: b.SubPath, propagation: b.Propagation}
+			err = bind.bind(ctx, client, newName, b.ID.App, b.ID.MountPoint)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Fprintf(ctx.Stdout, "Volume %q successfully cloned to %q.\n\n", sourceName, newName)
+	volume, err := c.conn.getVolume(client, newName)
+	if err != nil {
+		return err
+	}
+	if volume == nil {
+		return nil
+	}
+	return (&VolumeInfo{}).render(ctx, *volume)
+}
+
+type VolumeResize struct {
+	fs     *gnuflag.FlagSet
+	optKey string
+	quiet  bool
+	conn   volumeConn
+}
+
+func (c *VolumeResize) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-resize",
+		Usage: "volume resize <volume-name> <size> [--opt-key <key>]",
+		Desc: `Changes the capacity of a persistent volume.
+
+The size must be a quantity such as 512Mi or 10Gi. It is stored in the volume
+option given by [[--opt-key]], which defaults to "capacity".`,
+		MinArgs: 2,
+		MaxArgs: 2,
+	}
+}
+
+func (c *VolumeResize) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("volume-resize", gnuflag.ExitOnError)
+		c.fs.StringVar(&c.optKey, "opt-key", "capacity", "the volume option that holds the capacity")
+		c.fs.BoolVar(&c.quiet, "quiet", false, volumeQuietDesc)
+		c.fs.BoolVar(&c.quiet, "q", false, volumeQuietDesc)
+		c.conn.addFlags(c.fs)
+	}
+	return c.fs
+}
+
+func (c *VolumeResize) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeExitCode(&err)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	volumeName, size := ctx.Args[0], ctx.Args[1]
+	if _, err := resource.ParseQuantity(size); err != nil {
+		return fmt.Errorf("invalid size %q: %w", size, err)
+	}
+	optKey := c.optKey
+	if optKey == "" {
+		optKey = "capacity"
+	}
+	volume, err := c.conn.getVolume(client, volumeName)
+	if err != nil {
+		return err
+	}
+	if volume == nil {
+		return fmt.Errorf("volume %q not found", volumeName)
+	}
+	oldSize := volume.Opts[optKey]
+	opts := make(map[string]string, len(volume.Opts)+1)
+	for k, v := range volume.Opts {
+		opts[k] = v
+	}
+	opts[optKey] = size
+	err = c.conn.updateVolume(client, volumeTypes.Volume{
+		Name:      volume.Name,
+		Plan:      volumeTypes.VolumePlan{Name: volume.Plan.Name},
+		Pool:      volume.Pool,
+		TeamOwner: volume.TeamOwner,
+		Opts:      opts,
+	})
+	if err != nil {
+		return err
+	}
+	if !c.quiet {
+		fmt.Fprintf(ctx.Stdout, "Volume %q successfully resized from %s to %s.\n", volumeName, valueOrDash(oldSize), size)
+	}
+	return nil
+}
+
+type VolumeUsage struct {
+	fs      *gnuflag.FlagSet
+	groupBy string
+	raw     bool
+	conn    volumeConn
+}
+
+func (c *VolumeUsage) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-usage",
+		Usage: "volume usage [--group-by pool|team] [--raw]",
+		Desc: `Summarizes persistent volumes, showing the number of volumes and their total
+capacity grouped by pool or team.
+
+Volumes without a capacity, or with a capacity that can't be parsed, are
+counted in the Unknown column and left out of the total.
+
+Totals given as a plain number of bytes are displayed in binary units, such
+as 10.0 GiB, unless --raw is used.`,
+		MinArgs: 0,
+		MaxArgs: 0,
+	}
+}
+
+func (c *VolumeUsage) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("volume-usage", gnuflag.ExitOnError)
+		c.fs.StringVar(&c.groupBy, "group-by", "pool", "Group volumes by pool or team")
+		c.fs.BoolVar(&c.raw, "raw", false, volumeRawDesc)
+		c.conn.addFlags(c.fs)
+	}
+	return c.fs
+}
+
+type volumeUsageGroup struct {
+	count    int
+	unknown  int
+	capacity resource.Quantity
+}
+
+func (c *VolumeUsage) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeExitCode(&err)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	var groupKey func(volumeTypes.Volume) string
+	var header string
+	switch c.groupBy {
+	case "", "pool":
+		groupKey = func(v volumeTypes.Volume) string { return v.Pool }
+		header = "Pool"
+	case "team":
+		groupKey = func(v volumeTypes.Volume) string { return v.TeamOwner }
+		header = "Team"
+	default:
+		return fmt.Errorf("invalid group %q, valid options are: pool, team", c.groupBy)
+	}
+	volumes, err := c.conn.listVolumes(client, url.Values{})
+	if err != nil {
+		return err
+	}
+	if len(volumes) == 0 {
+		fmt.Fprintln(ctx.Stdout, "No volumes available.")
+		return nil
+	}
+	groups := map[string]*volumeUsageGroup{}
+	for _, v := range volumes {
+		key := groupKey(v)
+		group := groups[key]
+		if group == nil {
+			group = &volumeUsageGroup{}
+			groups[key] = group
+		}
+		group.count++
+		capacity, err := resource.ParseQuantity(volumeCapacity(v))
+		if err != nil {
+			group.unknown++
+			continue
+		}
+		group.capacity.Add(capacity)
+	}
+	tbl := tablecli.NewTable()
+	tbl.Headers = tablecli.Row{header, "Volumes", "Capacity", "Unknown"}
+	tbl.LineSeparator = true
+	for key, group := range groups {
+		capacity := "-"
+		if group.count > group.unknown {
+			capacity = group.capacity.String()
+			if !c.raw {
+				capacity = humanizeCapacity(capacity)
+			}
+		}
+		tbl.AddRow(tablecli.Row{
+			valueOrDash(key),
+			strconv.Itoa(group.count),
+			capacity,
+			strconv.Itoa(group.unknown),
+		})
+	}
+	tbl.Sort()
+	fmt.Fprint(ctx.Stdout, tbl.String())
+	return nil
+}
+
+type VolumeExport struct {
+	fs        *gnuflag.FlagSet
+	filter    volumeFilter
+	json      bool
+	file      string
+	withBinds bool
+	conn      volumeConn
+}
+
+func (c *VolumeExport) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-export",
+		Usage: "volume export [--json] [--file <path>] [--with-binds] [filter flags]...",
+		Desc: `Exports persistent volumes as a YAML or JSON manifest.
+
+The manifest is a list of volumes that can be given to volume-import to
+recreate them. It accepts the same filters as volume-list. Binds are only
+exported when [[--with-binds]] is used.`,
+		MinArgs: 0,
+		MaxArgs: 0,
+	}
+}
+
+func (c *VolumeExport) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("volume-export", gnuflag.ExitOnError)
+		c.filter.addFlags(c.fs)
+		c.fs.BoolVar(&c.json, "json", false, "Export in JSON format instead of YAML")
+		c.fs.StringVar(&c.file, "file", "", "Write the manifest to this file instead of the standard output")
+		c.fs.StringVar(&c.file, "f", "", "Write the manifest to this file instead of the standard output")
+		c.fs.BoolVar(&c.withBinds, "with-binds", false, "Include the binds of each volume")
+		c.conn.addFlags(c.fs)
+	}
+	return c.fs
+}
+
+func (c *VolumeExport) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeError(ctx, &err, &c.json)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	c.filter.pool = flagOrEnv(c.filter.pool, volumePoolEnv)
+	c.filter.teamOwner = flagOrEnv(c.filter.teamOwner, volumeTeamEnv)
+	if err := c.filter.validate(); err != nil {
+		return err
+	}
+	qs, err := c.filter.queryString()
+	if err != nil {
+		return err
+	}
+	volumes, err := c.conn.listVolumes(client, qs)
+	if err != nil {
+		return err
+	}
+	volumes = c.filter.apply(volumes)
+	sort.Slice(volumes, func(i, j int) bool {
+		return volumes[i].Name < volumes[j].Name
+	})
+	for i := range volumes {
+		volumes[i].Status = ""
+		if !c.withBinds {
+			volumes[i].Binds = nil
+		}
+	}
+	var data []byte
+	if c.json {
+		data, err = json.MarshalIndent(volumes, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(volumes)
+	}
+	if err != nil {
+		return err
+	}
+	if c.file == "" {
+		_, err = ctx.Stdout.Write(data)
+		return err
+	}
+	f, err := filesystem().OpenFile(c.file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Stdout, "Exported %d volume(s) to %s.\n", len(volumes), c.file)
+	return nil
+}
+
+type VolumeImport struct {
+	fs        *gnuflag.FlagSet
+	update    bool
+	withBinds bool
+	noRestart bool
+	conn      volumeConn
+}
+
+func (c *VolumeImport) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-import",
+		Usage: "volume import <file> [--update] [--with-binds] [--no-restart]",
+		Desc: `Creates the persistent volumes described in a YAML or JSON manifest, as
+generated by volume-export.
+
+Volumes that already exist are skipped, unless [[--update]] is used, in which
+case they are updated to match the manifest. With [[--with-binds]], the binds
+listed in the manifest are also created. A summary of what happened to each
+volume is displayed at the end.`,
+		MinArgs: 1,
+		MaxArgs: 1,
+	}
+}
+
+func (c *VolumeImport) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("volume-import", gnuflag.ExitOnError)
+		c.fs.BoolVar(&c.update, "update", false, "update volumes that already exist instead of skipping them")
+		c.fs.BoolVar(&c.withBinds, "with-binds", false, "create the binds listed in the manifest")
+		c.fs.BoolVar(&c.noRestart, "no-restart", false, "prevents restarting the applications when creating binds")
+		c.conn.addFlags(c.fs)
+	}
+	return c.fs
+}
+
+func (c *VolumeImport) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeExitCode(&err)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	ctx.RawOutput()
+	f, err := filesystem().Open(ctx.Args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	var manifest []volumeTypes.Volume
+	if err = yaml.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("unable to parse manifest %q: %w", ctx.Args[0], err)
+	}
+	existing, err := c.conn.listVolumes(client, url.Values{})
+	if err != nil {
+		return err
+	}
+	existingBinds := map[string]map[volumeTypes.VolumeBindID]struct{}{}
+	for _, v := range existing {
+		binds := map[volumeTypes.VolumeBindID]struct{}{}
+		for _, b := range v.Binds {
+			binds[volumeTypes.VolumeBindID{App: b.ID.App, MountPoint: b.ID.MountPoint}] = struct{}{}
+		}
+		existingBinds[v.Name] = binds
+	}
+	summary := make([]string, 0, len(manifest))
+	var failed int
+	for _, v := range manifest {
+		result, err := c.importVolume(ctx, client, v, existingBinds)
+		if err != nil {
+			failed++
+			fmt.Fprintf(ctx.Stderr, "Failed to import volume %q: %v\n", v.Name, err)
+			result = fmt.Sprintf("failed (%v)", err)
+		}
+		summary = append(summary, fmt.Sprintf("%s: %s", v.Name, result))
+	}
+	fmt.Fprintln(ctx.Stdout, "Summary:")
+	for _, line := range summary {
+		fmt.Fprintf(ctx.Stdout, "  %s\n", line)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to import %d of %d volumes", failed, len(manifest))
+	}
+	return nil
+}
+
+func (c *VolumeImport) importVolume(ctx *cmd.Context, client *cmd.Client, v volumeTypes.Volume, existingBinds map[string]map[volumeTypes.VolumeBindID]struct{}) (string, error) {
+	if v.Name == "" {
+		return "", errors.New("volume name is required")
+	}
+	vol := volumeTypes.Volume{
+		Name:      v.Name,
+		Plan:      volumeTypes.VolumePlan{Name: v.Plan.Name},
+		Pool:      v.Pool,
+		TeamOwner: v.TeamOwner,
+		Opts:      v.Opts,
+	}
+	var result string
+	binds, exists := existingBinds[v.Name]
+	switch {
+	case exists && !c.update:
+		return "skipped (already exists)", nil
+	case exists:
+		fmt.Fprintf(ctx.Stdout, "Updating volume %q...\n", v.Name)
+		if err := c.conn.updateVolume(client, vol); err != nil {
+			return "", err
+		}
+		result = "updated"
+	default:
+		fmt.Fprintf(ctx.Stdout, "Creating volume %q...\n", v.Name)
+		if err := c.conn.createVolume(client, vol); err != nil {
+			return "", err
+		}
+		result = "created"
+	}
+	if !c.withBinds {
+		return result, nil
+	}
+	var bound int
+	for _, b := range v.Binds {
+		if _, ok := binds[volumeTypes.VolumeBindID{App: b.ID.App, MountPoint: b.ID.MountPoint}]; ok {
+			continue
+		}
+		fmt.Fprintf(ctx.Stdout, "Binding volume %q to app %q at %q...\n", v.Name, b.ID.App, b.ID.MountPoint)
+		bind := &VolumeBind{conn: c.conn, readOnly: b.ReadOnly, noRestart: c.noRestart}
+		if err := bind.bind(ctx, client, v.Name, b.ID.App, b.ID.MountPoint); err != nil {
+			return "", fmt.Errorf("volume %s but binding to app %q failed: %w", result, b.ID.App, err)
+		}
+		bound++
+	}
+	if bound > 0 {
+		result = fmt.Sprintf("%s, %d bind(s) created", result, bound)
+	}
+	return result, nil
+}
+
+type VolumeCreateBatch struct {
+	fs   *gnuflag.FlagSet
+	conn volumeConn
+}
+
+func (c *VolumeCreateBatch) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-create-batch",
+		Usage: "volume create-batch <csv-file>",
+		Desc: `Creates the persistent volumes described in a CSV file, one per row.
+
+The columns are name, plan, pool and team, followed by any number of volume
+options. When the first row starts with a "name" column, it's taken as a
+header and the names of the extra columns are used as the option keys, for
+instance:
+
+    name,plan,pool,team,capacity
+    vol1,nfs,pool1,team1,1Gi
+
+Without a header, extra columns must be given as key=value. Empty pool and
+team cells fall back to the TSURU_POOL and TSURU_TEAM environment
+variables. Rows that fail don't stop the remaining ones from being created,
+and a summary is displayed at the end.`,
+		MinArgs: 1,
+		MaxArgs: 1,
+	}
+}
+
+func (c *VolumeCreateBatch) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("volume-create-batch", gnuflag.ExitOnError)
+		c.conn.addFlags(c.fs)
+	}
+	return c.fs
+}
+
+func (c *VolumeCreateBatch) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeExitCode(&err)
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
+	}
+	f, err := filesystem().Open(ctx.Args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("unable to parse CSV file %q: %w", ctx.Args[0], err)
+	}
+	var header []string
+	if len(records) > 0 && len(records[0]) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "name") {
+		header, records = records[0], records[1:]
+	}
+	summary := make([]string, 0, len(records))
+	var failed int
+	for i, record := range records {
+		line := i + 1
+		if header != nil {
+			line++
+		}
+		vol, err := volumeFromCSV(header, record)
+		if err == nil {
+			fmt.Fprintf(ctx.Stdout, "Creating volume %q...\n", vol.Name)
+			err = c.conn.createVolume(client, vol)
+		}
+		name := vol.Name
+		if name == "" {
+			name = fmt.Sprintf("line %d", line)
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(ctx.Stderr, "Failed to create volume from line %d: %v\n", line, err)
+			summary = append(summary, fmt.Sprintf("%s: failed (%v)", name, err))
+			continue
+		}
+		summary = append(summary, fmt.Sprintf("%s: created", name))
+	}
+	fmt.Fprintln(ctx.Stdout, "Summary:")
+	for _, line := range summary {
+		fmt.Fprintf(ctx.Stdout, "  %s\n", line)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to create %d of %d volumes", failed, len(records))
+	}
+	return nil
+}
+
+// volumeFromCSV builds a volume from a CSV record, using header, when
+// present, to name the option columns.
+func volumeFromCSV(header, record []string) (volumeTypes.Volume, error) {
+	field := func(i int) string {
+		if i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	vol := volumeTypes.Volume{
+		Name:      field(0),
+		Plan:      volumeTypes.VolumePlan{Name: field(1)},
+		Pool:      flagOrEnv(field(2), volumePoolEnv),
+		TeamOwner: flagOrEnv(field(3), volumeTeamEnv),
+	}
+	if vol.Name == "" {
+		return vol, errors.New("volume name is required")
+	}
+	if vol.Plan.Name == "" {
+		return vol, errors.New("volume plan is required")
+	}
+	for i := 4; i < len(record); i++ {
+		value := field(i)
+		if value == "" {
+			continue
+		}
+		var key string
+		if i < len(header) {
+			key = strings.TrimSpace(header[i])
+		} else {
+			parts := strings.SplitN(value, "=", 2)
+			if len(parts) != 2 {
+				return vol, fmt.Errorf("invalid option %q, expected key=value", value)
+			}
+			key, value = parts[0], parts[1]
+		}
+		if vol.Opts == nil {
+			vol.Opts = map[string]string{}
+		}
+		vol.Opts[key] = value
 	}
-	tbl.Sort()
-	fmt.Fprint(ctx.Stdout, tbl.String())
-	return nil
+	return vol, nil
 }
 
-type VolumeInfo struct {
+type VolumeMove struct {
+	cmd.ConfirmationCommand
 	fs   *gnuflag.FlagSet
-	json bool
+	conn volumeConn
 }
 
-func (c *VolumeInfo) Flags() *gnuflag.FlagSet {
-	if c.fs == nil {
-		c.fs = gnuflag.NewFlagSet("volume-info", gnuflag.ContinueOnError)
-		c.fs.BoolVar(&c.json, "json", false, "Show JSON")
+func (c *VolumeMove) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-move",
+		Usage: "volume move <volume-name> <new-pool> [-y/--assume-yes]",
+		Desc: `Moves a persistent volume to another pool.
+
+Applications mounting the volume may be disrupted while it's moved. Some
+backends are unable to move volume data between pools, in which case the
+volume can be copied with volume-clone and the original removed with
+volume-delete.`,
+		MinArgs: 2,
+		MaxArgs: 2,
 	}
-	return c.fs
 }
 
-func (c *VolumeInfo) Info() *cmd.Info {
-	return &cmd.Info{
-		Name:    "volume-info",
-		Usage:   "volume info <volume>",
-		Desc:    `Get a volume.`,
-		MinArgs: 1,
-		MaxArgs: 1,
+func (c *VolumeMove) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		fs := gnuflag.NewFlagSet("volume-move", gnuflag.ExitOnError)
+		c.conn.addFlags(fs)
+		c.fs = cmd.MergeFlagSet(c.ConfirmationCommand.Flags(), fs)
 	}
+	return c.fs
 }
 
-func (c *VolumeInfo) Run(ctx *cmd.Context, client *cmd.Client) error {
-	volumeName := ctx.Args[0]
-	u, err := cmd.GetURLVersion("1.4", "/volumes/"+volumeName)
-	if err != nil {
+func (c *VolumeMove) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeExitCode(&err)
+	if err := c.conn.apply(ctx, client); err != nil {
 		return err
 	}
-	request, err := http.NewRequest("GET", u, nil)
+	volumeName, newPool := ctx.Args[0], ctx.Args[1]
+	volume, err := c.conn.getVolume(client, volumeName)
 	if err != nil {
 		return err
 	}
-	rsp, err := client.Do(request)
-	if err != nil {
-		return err
+	if volume == nil {
+		return fmt.Errorf("volume %q not found", volumeName)
 	}
-	defer rsp.Body.Close()
-	if rsp.StatusCode == http.StatusNoContent {
-		fmt.Fprintln(ctx.Stdout, "No volumes available.")
-		return nil
+	if volume.Pool == newPool {
+		return fmt.Errorf("volume %q is already in pool %q", volumeName, newPool)
 	}
-	data, err := io.ReadAll(rsp.Body)
-	if err != nil {
-		return err
+	question := fmt.Sprintf("Are you sure you want to move volume %q from pool %q to %q?", volumeName, volume.Pool, newPool)
+	if len(volume.Binds) > 0 {
+		question = fmt.Sprintf("Volume %q is bound %d time(s) and its apps may be disrupted. %s", volumeName, len(volume.Binds), question)
 	}
-	var volume volumeTypes.Volume
-	err = json.Unmarshal(data, &volume)
-	if err != nil {
-		return err
+	if !c.Confirm(ctx, question) {
+		return nil
 	}
-
-	if c.json {
-		return formatter.JSON(ctx.Stdout, volume)
+	err = c.conn.updateVolume(client, volumeTypes.Volume{
+		Name:      volume.Name,
+		Plan:      volumeTypes.VolumePlan{Name: volume.Plan.Name},
+		Pool:      newPool,
+		TeamOwner: volume.TeamOwner,
+		Opts:      volume.Opts,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to move volume %q to pool %q: %w\nIf the backend can't move volume data, use volume-clone to copy it to the new pool and volume-delete to remove the original", volumeName, newPool, err)
 	}
+	fmt.Fprintf(ctx.Stdout, "Volume %q successfully moved from pool %q to %q.\n", volumeName, volume.Pool, newPool)
+	return nil
+}
 
-	return c.render(ctx, volume)
+type VolumeDiff struct {
+	fs   *gnuflag.FlagSet
+	json bool
+	conn volumeConn
 }
 
-func (c *VolumeInfo) render(ctx *cmd.Context, volume volumeTypes.Volume) error {
-	fmt.Fprintf(ctx.Stdout, "Name: %s\nPlan: %s\nPool: %s\nTeam: %s\n",
-		volume.Name,
-		volume.Plan.Name,
-		volume.Pool,
-		volume.TeamOwner,
-	)
-	bindTable := tablecli.NewTable()
-	bindTable.Headers = tablecli.Row([]string{"App", "MountPoint", "Mode"})
-	bindTable.LineSeparator = true
-	for _, b := range volume.Binds {
-		mode := "rw"
-		if b.ReadOnly {
-			mode = "ro"
-		}
-		bindTable.AddRow(tablecli.Row([]string{b.ID.App, b.ID.MountPoint, mode}))
-	}
-	fmt.Fprintf(ctx.Stdout, "\nBinds:\n")
-	fmt.Fprint(ctx.Stdout, bindTable.String())
-	planOptsTable := tablecli.NewTable()
-	planOptsTable.Headers = []string{"Key", "Value"}
-	planOptsTable.LineSeparator = true
-	for k, v := range volume.Plan.Opts {
-		planOptsTable.AddRow([]string{k, fmt.Sprintf("%v", v)})
+func (c *VolumeDiff) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-diff",
+		Usage: "volume diff <volume-a> <volume-b> [--json]",
+		Desc: `Compares two persistent volumes, displaying the differences in their pool,
+team, plan, plan opts and opts.
+
+Lines starting with "-" show values of the first volume and lines starting
+with "+" show values of the second one. Keys that are present in only one of
+the volumes are displayed with a single line. When writing to a terminal, the
+lines are colored, unless the NO_COLOR environment variable is set.`,
+		MinArgs: 2,
+		MaxArgs: 2,
 	}
-	planOptsTable.Sort()
-	fmt.Fprint(ctx.Stdout, "\nPlan Opts:\n")
-	fmt.Fprint(ctx.Stdout, planOptsTable.String())
-	optsTable := tablecli.NewTable()
-	optsTable.Headers = []string{"Key", "Value"}
-	optsTable.LineSeparator = true
-	for k, v := range volume.Opts {
-		optsTable.AddRow([]string{k, fmt.Sprintf("%v", v)})
+}
+
+func (c *VolumeDiff) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("volume-diff", gnuflag.ExitOnError)
+		c.fs.BoolVar(&c.json, "json", false, "Display the differences in JSON format")
+		c.conn.addFlags(c.fs)
 	}
-	optsTable.Sort()
-	fmt.Fprintf(ctx.Stdout, "\nOpts:\n")
-	fmt.Fprint(ctx.Stdout, optsTable.String())
-	return nil
+	return c.fs
+}
+
+// volumeDiffValue holds the values of a key in each of the compared volumes,
+// a nil value means the key is missing from that volume.
+type volumeDiffValue struct {
+	A interface{} `json:"a,omitempty"`
+	B interface{} `json:"b,omitempty"`
 }
 
-type VolumePlansList struct{}
+type volumeDiff struct {
+	A          string                     `json:"a"`
+	B          string                     `json:"b"`
+	Attributes map[string]volumeDiffValue `json:"attributes"`
+	PlanOpts   map[string]volumeDiffValue `json:"planOpts"`
+	Opts       map[string]volumeDiffValue `json:"opts"`
+}
 
-func (c *VolumePlansList) Info() *cmd.Info {
-	return &cmd.Info{
-		Name:    "volume-plan-list",
-		Usage:   "volume plan list",
-		Desc:    `Lists existing volume plans.`,
-		MinArgs: 0,
-		MaxArgs: 0,
-	}
+func (d *volumeDiff) isEmpty() bool {
+	return len(d.Attributes) == 0 && len(d.PlanOpts) == 0 && len(d.Opts) == 0
 }
 
-func (c *VolumePlansList) Run(ctx *cmd.Context, client *cmd.Client) error {
-	u, err := cmd.GetURLVersion("1.4", "/volumeplans")
-	if err != nil {
-		return err
-	}
-	request, err := http.NewRequest("GET", u, nil)
-	if err != nil {
-		return err
-	}
-	rsp, err := client.Do(request)
-	if err != nil {
+func (c *VolumeDiff) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeError(ctx, &err, &c.json)
+	if err := c.conn.apply(ctx, client); err != nil {
 		return err
 	}
-	defer rsp.Body.Close()
-	var plans map[string][]volumeTypes.VolumePlan
-	if rsp.StatusCode != http.StatusNoContent {
-		data, err := io.ReadAll(rsp.Body)
+	var volumes [2]*volumeData
+	for i, name := range ctx.Args {
+		volume, err := c.conn.getVolume(client, name)
 		if err != nil {
 			return err
 		}
-		err = json.Unmarshal(data, &plans)
-		if err != nil {
-			return err
+		if volume == nil {
+			return fmt.Errorf("volume %q not found", name)
 		}
+		volumes[i] = volume
 	}
-	return c.render(ctx, plans)
+	a, b := volumes[0], volumes[1]
+	diff := volumeDiff{
+		A: a.Name,
+		B: b.Name,
+		Attributes: diffVolumeMaps(
+			map[string]interface{}{"pool": a.Pool, "team": a.TeamOwner, "plan": a.Plan.Name},
+			map[string]interface{}{"pool": b.Pool, "team": b.TeamOwner, "plan": b.Plan.Name},
+		),
+		PlanOpts: diffVolumeMaps(a.Plan.Opts, b.Plan.Opts),
+		Opts:     diffVolumeMaps(stringMapToInterface(a.Opts), stringMapToInterface(b.Opts)),
+	}
+	if c.json {
+		return formatter.JSON(ctx.Stdout, diff)
+	}
+	if diff.isEmpty() {
+		fmt.Fprintf(ctx.Stdout, "Volumes %q and %q are identical.\n", a.Name, b.Name)
+		return nil
+	}
+	colorize := colorsEnabled(ctx.Stdout)
+	fmt.Fprintln(ctx.Stdout, volumeDiffLine("--- "+a.Name, "red", colorize))
+	fmt.Fprintln(ctx.Stdout, volumeDiffLine("+++ "+b.Name, "green", colorize))
+	renderVolumeDiffSection(ctx.Stdout, "Attributes", diff.Attributes, colorize)
+	renderVolumeDiffSection(ctx.Stdout, "Plan Opts", diff.PlanOpts, colorize)
+	renderVolumeDiffSection(ctx.Stdout, "Opts", diff.Opts, colorize)
+	return nil
 }
 
-func (c *VolumePlansList) render(ctx *cmd.Context, plans map[string][]volumeTypes.VolumePlan) error {
-	tbl := tablecli.NewTable()
-	tbl.Headers = tablecli.Row{"Plan", "Provisioner", "Opts"}
-	tbl.LineSeparator = true
-	for provisioner, provPlans := range plans {
-		for _, p := range provPlans {
-			var opts []string
-			for k, v := range p.Opts {
-				opts = append(opts, fmt.Sprintf("%s: %v", k, v))
-			}
-			sort.Strings(opts)
-			tbl.AddRow(tablecli.Row{
-				p.Name,
-				provisioner,
-				strings.Join(opts, "\n"),
-			})
-		}
+func volumeDiffLine(line, color string, colorize bool) string {
+	if !colorize {
+		return line
 	}
-	tbl.SortByColumn(0, 1)
-	fmt.Fprint(ctx.Stdout, tbl.String())
-	return nil
+	return cmd.Colorfy(line, color, "", "")
 }
 
-type VolumeDelete struct{}
+// diffVolumeMaps returns the keys whose values differ between a and b,
+// including keys present in only one of them.
+func diffVolumeMaps(a, b map[string]interface{}) map[string]volumeDiffValue {
+	diff := map[string]volumeDiffValue{}
+	for k, va := range a {
+		vb, ok := b[k]
+		if !ok || !reflect.DeepEqual(va, vb) {
+			diff[k] = volumeDiffValue{A: va, B: vb}
+		}
+	}
+	for k, vb := range b {
+		if _, ok := a[k]; !ok {
+			diff[k] = volumeDiffValue{B: vb}
+		}
+	}
+	return diff
+}
 
-func (c *VolumeDelete) Info() *cmd.Info {
-	return &cmd.Info{
-		Name:    "volume-delete",
-		Usage:   "volume delete <volume-name>",
-		Desc:    `Delete an existing persistent volume.`,
-		MinArgs: 1,
-		MaxArgs: 1,
+func stringMapToInterface(m map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
 	}
+	return result
 }
 
-func (c *VolumeDelete) Run(ctx *cmd.Context, client *cmd.Client) error {
-	volumeName := ctx.Args[0]
-	u, err := cmd.GetURLVersion("1.4", "/volumes/"+volumeName)
-	if err != nil {
-		return err
+func renderVolumeDiffSection(w io.Writer, title string, diff map[string]volumeDiffValue, colorize bool) {
+	if len(diff) == 0 {
+		return
 	}
-	request, err := http.NewRequest("DELETE", u, nil)
-	if err != nil {
-		return err
+	keys := make([]string, 0, len(diff))
+	for k := range diff {
+		keys = append(keys, k)
 	}
-	_, err = client.Do(request)
-	if err != nil {
-		return err
+	sort.Strings(keys)
+	fmt.Fprintf(w, "@@ %s @@\n", title)
+	for _, k := range keys {
+		d := diff[k]
+		if d.A != nil {
+			fmt.Fprintln(w, volumeDiffLine(fmt.Sprintf("-%s: %s", k, formatOptValue(d.A)), "red", colorize))
+		}
+		if d.B != nil {
+			fmt.Fprintln(w, volumeDiffLine(fmt.Sprintf("+%s: %s", k, formatOptValue(d.B)), "green", colorize))
+		}
 	}
-	fmt.Fprint(ctx.Stdout, "Volume successfully deleted.\n")
-	return nil
 }
 
-type VolumeBind struct {
-	cmd.AppNameMixIn
-	fs        *gnuflag.FlagSet
-	readOnly  bool
-	noRestart bool
+type VolumeEvents struct {
+	fs    *gnuflag.FlagSet
+	json  bool
+	limit int
+	conn  volumeConn
 }
 
-func (c *VolumeBind) Info() *cmd.Info {
+func (c *VolumeEvents) Info() *cmd.Info {
 	return &cmd.Info{
-		Name:    "volume-bind",
-		Usage:   "volume bind <volume-name> <mount point> [-a/--app <appname>] [-r/--readonly] [--no-restart]",
-		Desc:    `Binds an existing volume to an application.`,
-		MinArgs: 2,
-		MaxArgs: 2,
+		Name:  "volume-events",
+		Usage: "volume events <volume-name> [--limit <n>] [--json]",
+		Desc: `Lists the events targeting a volume, such as its creation, updates and binds,
+showing when they started, their kind, who triggered them and whether they
+succeeded.
+
+The most recent events are listed first. The server returns at most 100
+events, use --limit to get fewer of them.`,
+		MinArgs: 1,
+		MaxArgs: 1,
 	}
 }
 
-func (c *VolumeBind) Flags() *gnuflag.FlagSet {
+func (c *VolumeEvents) Flags() *gnuflag.FlagSet {
 	if c.fs == nil {
-		c.fs = c.AppNameMixIn.Flags()
-		desc := "the volume will be available only for reading"
-		c.fs.BoolVar(&c.readOnly, "readonly", false, desc)
-		c.fs.BoolVar(&c.readOnly, "r", false, desc)
-		c.fs.BoolVar(&c.noRestart, "no-restart", false, "prevents restarting the application")
+		c.fs = gnuflag.NewFlagSet("volume-events", gnuflag.ExitOnError)
+		c.fs.BoolVar(&c.json, "json", false, "Show JSON")
+		c.fs.IntVar(&c.limit, "limit", 0, "maximum number of events to display")
+		c.conn.addFlags(c.fs)
 	}
 	return c.fs
 }
 
-func (c *VolumeBind) Run(ctx *cmd.Context, client *cmd.Client) error {
-	ctx.RawOutput()
-	volumeName := ctx.Args[0]
-	appName, err := c.AppName()
-	if err != nil {
-		return err
+func (c *VolumeEvents) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeError(ctx, &err, &c.json)
+	if c.limit < 0 {
+		return fmt.Errorf("invalid limit %d, it must be a positive number", c.limit)
 	}
-	bind := struct {
-		App        string
-		MountPoint string
-		ReadOnly   bool
-		NoRestart  bool
-	}{
-		App:        appName,
-		MountPoint: ctx.Args[1],
-		ReadOnly:   c.readOnly,
-		NoRestart:  c.noRestart,
+	if err := c.conn.apply(ctx, client); err != nil {
+		return err
 	}
-	val, err := form.EncodeToValues(bind)
+	evts, err := listVolumeEvents(client, ctx.Args[0], c.limit)
 	if err != nil {
 		return err
 	}
-	body := strings.NewReader(val.Encode())
-	u, err := cmd.GetURLVersion("1.4", fmt.Sprintf("/volumes/%s/bind", volumeName))
+	if c.json {
+		result := []*orderedmap.OrderedMap{}
+		for i := range evts {
+			o, err := eventJSONFriendly(&evts[i])
+			if err != nil {
+				return err
+			}
+			result = append(result, o)
+		}
+		return formatter.JSON(ctx.Stdout, result)
+	}
+	if len(evts) == 0 {
+		fmt.Fprintf(ctx.Stdout, "No events found for volume %q.\n", ctx.Args[0])
+		return nil
+	}
+	tbl := tablecli.NewTable()
+	tbl.Headers = tablecli.Row{"Start (duration)", "Kind", "Owner", "Success"}
+	for i := range evts {
+		evt := &evts[i]
+		success := "…"
+		var duration *time.Duration
+		if !evt.Running {
+			timeDiff := evt.EndTime.Sub(evt.StartTime)
+			duration = &timeDiff
+			success = strconv.FormatBool(evt.Error == "")
+		}
+		tbl.AddRow(tablecli.Row{
+			formatter.FormatDateAndDuration(evt.StartTime, duration),
+			evt.Kind.Name,
+			evt.Owner.Name,
+			success,
+		})
+	}
+	fmt.Fprint(ctx.Stdout, tbl.String())
+	return nil
+}
+
+// listVolumeEvents returns the events whose target is the volume named
+// volumeName, at most limit of them when limit is greater than zero.
+func listVolumeEvents(client *cmd.Client, volumeName string, limit int) ([]event.Event, error) {
+	qs := url.Values{}
+	qs.Set("target.type", string(event.TargetTypeVolume))
+	qs.Set("target.value", volumeName)
+	if limit > 0 {
+		qs.Set("limit", strconv.Itoa(limit))
+	}
+	u, err := cmd.GetURLVersion("1.1", fmt.Sprintf("/events?%s", qs.Encode()))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	request, err := http.NewRequest("POST", u, body)
+	request, err := http.NewRequest("GET", u, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	resp, err := client.Do(request)
+	rsp, err := client.Do(request)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode == http.StatusNoContent {
+		return nil, nil
 	}
-	err = cmd.StreamJSONResponse(ctx.Stdout, resp)
+	data, err := io.ReadAll(rsp.Body)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	fmt.Fprint(ctx.Stdout, "Volume successfully bound.\n")
-	return nil
+	var evts []event.Event
+	if err := json.Unmarshal(data, &evts); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal %q: %s", string(data), err)
+	}
+	return evts, nil
 }
 
-type VolumeUnbind struct {
-	cmd.AppNameMixIn
-	fs        *gnuflag.FlagSet
-	noRestart bool
+type VolumePrune struct {
+	cmd.ConfirmationCommand
+	fs     *gnuflag.FlagSet
+	filter volumeFilter
+	dryRun bool
+	conn   volumeConn
 }
 
-func (c *VolumeUnbind) Info() *cmd.Info {
+func (c *VolumePrune) Info() *cmd.Info {
 	return &cmd.Info{
-		Name:    "volume-unbind",
-		Usage:   "volume unbind <volume-name> <mount point> [-a/--app <appname>]",
-		Desc:    `Unbinds a volume from an application.`,
-		MinArgs: 2,
-		MaxArgs: 2,
+		Name:  "volume-prune",
+		Usage: "volume prune [-o/--pool <pool>] [-t/--team <team>] [--dry-run] [-y/--assume-yes]",
+		Desc: `Deletes every persistent volume that isn't bound to any application.
+
+The volumes to be deleted are listed, along with their capacity when known,
+and a confirmation is asked before deleting them. The [[--pool]] and
+[[--team]] flags restrict the volumes considered, and have the same short
+forms as in volume-list.
+
+With [[--dry-run]], the volumes are listed and nothing is deleted.
+
+A failure deleting one of the volumes does not stop the others from being
+deleted. At the end, the number of volumes deleted and the total capacity
+reclaimed are displayed. Volumes whose capacity is unknown are left out of
+the total.`,
+		MinArgs: 0,
+		MaxArgs: 0,
 	}
 }
 
-func (c *VolumeUnbind) Flags() *gnuflag.FlagSet {
+func (c *VolumePrune) Flags() *gnuflag.FlagSet {
 	if c.fs == nil {
-		c.fs = c.AppNameMixIn.Flags()
-		c.fs.BoolVar(&c.noRestart, "no-restart", false, "prevents restarting the application")
+		fs := gnuflag.NewFlagSet("volume-prune", gnuflag.ExitOnError)
+		desc := "only delete volumes in this pool"
+		fs.StringVar(&c.filter.pool, "pool", "", desc)
+		fs.StringVar(&c.filter.pool, "o", "", desc)
+		desc = "only delete volumes owned by this team"
+		fs.StringVar(&c.filter.teamOwner, "team", "", desc)
+		fs.StringVar(&c.filter.teamOwner, "t", "", desc)
+		fs.BoolVar(&c.dryRun, "dry-run", false, "list the volumes that would be deleted without deleting them")
+		c.fs = cmd.MergeFlagSet(c.ConfirmationCommand.Flags(), fs)
+		c.conn.addFlags(c.fs)
 	}
 	return c.fs
 }
 
-func (c *VolumeUnbind) Run(ctx *cmd.Context, client *cmd.Client) error {
-	ctx.RawOutput()
-	volumeName := ctx.Args[0]
-	appName, err := c.AppName()
-	if err != nil {
+func (c *VolumePrune) Run(ctx *cmd.Context, client *cmd.Client) (err error) {
+	defer recordVolumeExitCode(&err)
+	if err := c.conn.apply(ctx, client); err != nil {
 		return err
 	}
-	bind := struct {
-		App        string
-		MountPoint string
-		NoRestart  bool
-	}{
-		App:        appName,
-		MountPoint: ctx.Args[1],
-		NoRestart:  c.noRestart,
-	}
-	val, err := form.EncodeToValues(bind)
-	if err != nil {
+	c.filter.unbound = true
+	if err := c.filter.validate(); err != nil {
 		return err
 	}
-	u, err := cmd.GetURLVersion("1.4", fmt.Sprintf("/volumes/%s/bind?%s", volumeName, val.Encode()))
+	qs, err := c.filter.queryString()
 	if err != nil {
 		return err
 	}
-	request, err := http.NewRequest("DELETE", u, nil)
+	volumes, err := c.conn.listVolumes(client, qs)
 	if err != nil {
 		return err
 	}
-	resp, err := client.Do(request)
-	if err != nil {
-		return err
+	volumes = c.filter.apply(volumes)
+	if len(volumes) == 0 {
+		fmt.Fprintln(ctx.Stdout, "No unbound volumes to delete.")
+		return nil
 	}
-	err = cmd.StreamJSONResponse(ctx.Stdout, resp)
-	if err != nil {
-		return err
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
+	if c.dryRun {
+		fmt.Fprintln(ctx.Stdout, "The following unbound volumes would be deleted:")
+	} else {
+		fmt.Fprintln(ctx.Stdout, "The following unbound volumes will be deleted:")
+	}
+	for _, v := range volumes {
+		if capacity := volumeCapacity(v); capacity != "" {
+			fmt.Fprintf(ctx.Stdout, " - %s (%s)\n", v.Name, humanizeCapacity(capacity))
+		} else {
+			fmt.Fprintf(ctx.Stdout, " - %s\n", v.Name)
+		}
+	}
+	if c.dryRun {
+		return nil
+	}
+	if !c.Confirm(ctx, fmt.Sprintf("Are you sure you want to delete %d volume(s)?", len(volumes))) {
+		return nil
+	}
+	var deleted, failures int
+	var reclaimed resource.Quantity
+	for _, v := range volumes {
+		if err = c.conn.removeVolume(client, v.Name); err != nil {
+			failures++
+			fmt.Fprintf(ctx.Stderr, "Failed to delete volume %q: %v\n", v.Name, err)
+			continue
+		}
+		deleted++
+		if capacity, err := resource.ParseQuantity(volumeCapacity(v)); err == nil {
+			reclaimed.Add(capacity)
+		}
+	}
+	if reclaimed.IsZero() {
+		fmt.Fprintf(ctx.Stdout, "Deleted %d volume(s).\n", deleted)
+	} else {
+		fmt.Fprintf(ctx.Stdout, "Deleted %d volume(s), reclaiming %s.\n", deleted, humanizeCapacity(reclaimed.String()))
+	}
+	if failures > 0 {
+		return fmt.Errorf("failed to delete %d of %d volumes", failures, len(volumes))
 	}
-	fmt.Fprint(ctx.Stdout, "Volume successfully unbound.\n")
 	return nil
 }
@@ -0,0 +1,179 @@
+// Copyright 2026 tsuru-client authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/cmd"
+)
+
+const volumeCompletionCacheTTL = 10 * time.Second
+
+var completionNow = time.Now
+
+var volumeCompletionKinds = map[string]func(*cmd.Client) ([]string, error){
+	"plans":   volumePlanCompletions,
+	"volumes": volumeNameCompletions,
+}
+
+type VolumeComplete struct{}
+
+func (c *VolumeComplete) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "volume-complete",
+		Usage: "volume complete <volumes|plans>",
+		Desc: `Prints completion candidates, one per line, for use by shell completion
+scripts. Results are cached for a few seconds, or a minute for plans, to
+avoid hitting the API on every key press.`,
+		MinArgs: 1,
+		MaxArgs: 1,
+	}
+}
+
+func (c *VolumeComplete) Run(ctx *cmd.Context, client *cmd.Client) error {
+	kind := ctx.Args[0]
+	complete, ok := volumeCompletionKinds[kind]
+	if !ok {
+		var kinds []string
+		for k := range volumeCompletionKinds {
+			kinds = append(kinds, k)
+		}
+		sort.Strings(kinds)
+		return fmt.Errorf("invalid completion kind %q, valid options are: %s", kind, strings.Join(kinds, ", "))
+	}
+	values, err := complete(client)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		fmt.Fprintln(ctx.Stdout, v)
+	}
+	return nil
+}
+
+func volumeNameCompletions(client *cmd.Client) ([]string, error) {
+	var conn volumeConn
+	u, err := conn.volumeURL("/volumes")
+	if err != nil {
+		return nil, err
+	}
+	return cachedCompletions("volumes", u, func() ([]string, error) {
+		volumes, err := conn.listVolumes(client, url.Values{})
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(volumes))
+		for _, v := range volumes {
+			names = append(names, v.Name)
+		}
+		sort.Strings(names)
+		return names, nil
+	})
+}
+
+// volumePlanCompletions lists the names of the volume plans, using the same
+// cache as the plan validation of volume-create.
+func volumePlanCompletions(client *cmd.Client) ([]string, error) {
+	plans, err := (&volumePlanCache{}).plans(&volumeConn{}, client)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]struct{}{}
+	var names []string
+	for _, provPlans := range plans {
+		for _, p := range provPlans {
+			if _, ok := seen[p.Name]; ok {
+				continue
+			}
+			seen[p.Name] = struct{}{}
+			names = append(names, p.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+type completionCache struct {
+	URL    string          `json:"url"`
+	User   string          `json:"user"`
+	Time   time.Time       `json:"time"`
+	Values json.RawMessage `json:"values"`
+}
+
+// cacheUser identifies the user whose token fetched the cached values, so
+// that switching accounts on the same target doesn't serve another user's
+// values. Only a hash of the token is stored.
+func cacheUser() string {
+	token, _ := cmd.ReadToken()
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedCompletions returns the values stored in the completion cache named
+// name, calling fetch and refreshing the cache when it's missing, expired or
+// was populated from a different target URL or by a different user.
+func cachedCompletions(name, url string, fetch func() ([]string, error)) ([]string, error) {
+	var values []string
+	if readCache("completion-"+name, url, volumeCompletionCacheTTL, &values) {
+		return values, nil
+	}
+	values, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	writeCache("completion-"+name, url, values)
+	return values, nil
+}
+
+// readCache decodes into v the values stored in the cache named name,
+// reporting whether it did. Caches that are older than ttl or were populated
+// from a different URL, such as another target, or by a different user are
+// ignored.
+func readCache(name, url string, ttl time.Duration, v interface{}) bool {
+	f, err := filesystem().Open(cmd.JoinWithUserDir(".tsuru", "cache", name+".json"))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	var cached completionCache
+	if err = json.NewDecoder(f).Decode(&cached); err != nil {
+		return false
+	}
+	if cached.URL != url || cached.User != cacheUser() || completionNow().Sub(cached.Time) >= ttl {
+		return false
+	}
+	return json.Unmarshal(cached.Values, v) == nil
+}
+
+// writeCache stores values in the cache named name, recording the URL they
+// were fetched from and the user who fetched them.
+func writeCache(name, url string, values interface{}) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return
+	}
+	path := cmd.JoinWithUserDir(".tsuru", "cache", name+".json")
+	// Failing to write the cache is not fatal, the values are fetched
+	// again next time.
+	if err := filesystem().MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	f, err := filesystem().OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	json.NewEncoder(f).Encode(completionCache{URL: url, User: cacheUser(), Time: completionNow(), Values: data})
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 tsuru-client authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/cmd"
+	"github.com/tsuru/tsuru/cmd/cmdtest"
+	"github.com/tsuru/tsuru/fs/fstest"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestVolumeCompleteInfo(c *check.C) {
+	c.Assert((&VolumeComplete{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeCompleteVolumesCached(c *check.C) {
+	fsystem = &fstest.RecordingFs{}
+	defer func() {
+		fsystem = nil
+		completionNow = time.Now
+	}()
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	completionNow = func() time.Time { return now }
+	var calls int
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: `[{"Name":"vol2"},{"Name":"vol1"}]`, Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			calls++
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	for i := 0; i < 2; i++ {
+		var stdout bytes.Buffer
+		ctx := cmd.Context{Args: []string{"volumes"}, Stdout: &stdout}
+		err := (&VolumeComplete{}).Run(&ctx, client)
+		c.Assert(err, check.IsNil)
+		c.Assert(stdout.String(), check.Equals, "vol1\nvol2\n")
+	}
+	c.Assert(calls, check.Equals, 1)
+	now = now.Add(volumeCompletionCacheTTL)
+	var stdout bytes.Buffer
+	ctx := cmd.Context{Args: []string{"volumes"}, Stdout: &stdout}
+	err := (&VolumeComplete{}).Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "vol1\nvol2\n")
+	c.Assert(calls, check.Equals, 2)
+}
+
+func (s *S) TestVolumeCompleteVolumesCachedPerUser(c *check.C) {
+	fsystem = &fstest.RecordingFs{}
+	defer func() {
+		fsystem = nil
+		os.Setenv("TSURU_TOKEN", "sometoken")
+	}()
+	var calls int
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: `[{"Name":"vol1"}]`, Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			calls++
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	for _, token := range []string{"token1", "token1", "token2"} {
+		os.Setenv("TSURU_TOKEN", token)
+		var stdout bytes.Buffer
+		ctx := cmd.Context{Args: []string{"volumes"}, Stdout: &stdout}
+		err := (&VolumeComplete{}).Run(&ctx, client)
+		c.Assert(err, check.IsNil)
+		c.Assert(stdout.String(), check.Equals, "vol1\n")
+	}
+	c.Assert(calls, check.Equals, 2)
+}
+
+func (s *S) TestVolumeCompletePlans(c *check.C) {
+	fsystem = &fstest.RecordingFs{}
+	defer func() { fsystem = nil }()
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{
+			Message: `{"kubernetes":[{"Name":"nfs"},{"Name":"ebs"}],"swarm":[{"Name":"nfs"}]}`,
+			Status:  http.StatusOK,
+		},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumeplans") && r.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	var stdout bytes.Buffer
+	ctx := cmd.Context{Args: []string{"plans"}, Stdout: &stdout}
+	err := (&VolumeComplete{}).Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "ebs\nnfs\n")
+}
+
+func (s *S) TestVolumeCompleteInvalidKind(c *check.C) {
+	var stdout bytes.Buffer
+	ctx := cmd.Context{Args: []string{"apps"}, Stdout: &stdout}
+	err := (&VolumeComplete{}).Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, `invalid completion kind "apps", valid options are: plans, volumes`)
+}
@@ -6,12 +6,22 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/ajg/form"
+	"github.com/tsuru/tablecli"
+	"github.com/tsuru/tsuru-client/tsuru/config"
 	"github.com/tsuru/tsuru/cmd"
 	"github.com/tsuru/tsuru/cmd/cmdtest"
+	"github.com/tsuru/tsuru/fs/fstest"
 	volumeTypes "github.com/tsuru/tsuru/types/volume"
 	"gopkg.in/check.v1"
 )
@@ -41,127 +51,139 @@ func (s *S) TestVolumeList(c *check.C) {
 	err := (&VolumeList{}).Run(&ctx, client)
 	c.Assert(err, check.IsNil)
 	result := stdout.String()
-	c.Assert(result, check.Equals, `+---------+------+-----------+-------+
-| Name    | Plan | Pool      | Team  |
-+---------+------+-----------+-------+
-| other   | nfs  | swarmpool | admin |
-+---------+------+-----------+-------+
-| vag-nfs | nfs  | kubepool  | admin |
-+---------+------+-----------+-------+
+	c.Assert(result, check.Equals, `+---------+------+-----------+-------+-------+
+| Name    | Plan | Pool      | Team  | Binds |
++---------+------+-----------+-------+-------+
+| other   | nfs  | swarmpool | admin | 0     |
++---------+------+-----------+-------+-------+
+| vag-nfs | nfs  | kubepool  | admin | 2     |
++---------+------+-----------+-------+-------+
 `)
 }
 
-func (s *S) TestVolumeListEmpty(c *check.C) {
+func (s *S) TestVolumeListPoolAndTeamFromEnv(c *check.C) {
+	os.Setenv("TSURU_POOL", "envpool")
+	os.Setenv("TSURU_TEAM", "envteam")
+	defer os.Unsetenv("TSURU_POOL")
+	defer os.Unsetenv("TSURU_TEAM")
 	var stdout, stderr bytes.Buffer
 	ctx := cmd.Context{
-		Args:   []string{},
 		Stdout: &stdout,
 		Stderr: &stderr,
 	}
 	trans := &cmdtest.ConditionalTransport{
 		Transport: cmdtest.Transport{Message: "", Status: http.StatusNoContent},
-		CondFunc: func(req *http.Request) bool {
-			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		CondFunc: func(r *http.Request) bool {
+			c.Assert(r.URL.Query().Get("pool"), check.Equals, "pool1")
+			c.Assert(r.URL.Query().Get("teamOwner"), check.Equals, "envteam")
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "GET"
 		},
 	}
 	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
-	err := (&VolumeList{}).Run(&ctx, client)
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--pool", "pool1"})
+	err := command.Run(&ctx, client)
 	c.Assert(err, check.IsNil)
-	result := stdout.String()
-	c.Assert(result, check.Equals, "No volumes available.\n")
-}
-
-func (s *S) TestVolumeInfoInfo(c *check.C) {
-	c.Assert((&VolumeInfo{}).Info(), check.NotNil)
+	c.Assert(stdout.String(), check.Equals, "No volumes available.\n")
 }
 
-func (s *S) TestVolumeInfo(c *check.C) {
+func (s *S) TestVolumeListSortByPool(c *check.C) {
 	var stdout, stderr bytes.Buffer
-	response := `
-		{"Name":"vol1","Pool":"kubepool","Plan":{"Name":"nfs","Opts":{"access-modes":"ReadWriteMany","plugin":"nfs"}},"TeamOwner":"admin","Status":"","Binds":[{"ID":{"App":"myapp","MountPoint":"/mymnt","Volume":"vag-nfs"},"ReadOnly":false},{"ID":{"App":"myapp","MountPoint":"/mymnt1","Volume":"vag-nfs"},"ReadOnly":false}],"Opts":{"capacity":"1Gi","path":"/home/vagrant/nfstest","server":"192.168.50.4"}}`
+	response := `[
+		{"Name":"a-vol","Pool":"zpool","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"b-vol","Pool":"apool","Plan":{"Name":"ebs"},"TeamOwner":"admin"}
+]`
 	ctx := cmd.Context{
-		Args:   []string{"vol1"},
+		Args:   []string{},
 		Stdout: &stdout,
 		Stderr: &stderr,
 	}
 	trans := &cmdtest.ConditionalTransport{
 		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
 		CondFunc: func(req *http.Request) bool {
-			return strings.HasSuffix(req.URL.Path, "/volumes/vol1") && req.Method == "GET"
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
 		},
 	}
 	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
-	command := &VolumeInfo{}
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--sort", "pool"})
 	err := command.Run(&ctx, client)
 	c.Assert(err, check.IsNil)
-	result := stdout.String()
-	c.Assert(result, check.Equals, `Name: vol1
-Plan: nfs
-Pool: kubepool
-Team: admin
-
-Binds:
-+-------+------------+------+
-| App   | MountPoint | Mode |
-+-------+------------+------+
-| myapp | /mymnt     | rw   |
-+-------+------------+------+
-| myapp | /mymnt1    | rw   |
-+-------+------------+------+
-
-Plan Opts:
-+--------------+---------------+
-| Key          | Value         |
-+--------------+---------------+
-| access-modes | ReadWriteMany |
-+--------------+---------------+
-| plugin       | nfs           |
-+--------------+---------------+
-
-Opts:
-+----------+-----------------------+
-| Key      | Value                 |
-+----------+-----------------------+
-| capacity | 1Gi                   |
-+----------+-----------------------+
-| path     | /home/vagrant/nfstest |
-+----------+-----------------------+
-| server   | 192.168.50.4          |
-+----------+-----------------------+
+	c.Assert(stdout.String(), check.Equals, `+-------+------+-------+-------+-------+
+| Name  | Plan | Pool  | Team  | Binds |
++-------+------+-------+-------+-------+
+| b-vol | ebs  | apool | admin | 0     |
++-------+------+-------+-------+-------+
+| a-vol | nfs  | zpool | admin | 0     |
++-------+------+-------+-------+-------+
 `)
 }
 
-func (s *S) TestVolumeInfoEmpty(c *check.C) {
-	var stdout, stderr bytes.Buffer
-	ctx := cmd.Context{
-		Args:   []string{"vol"},
-		Stdout: &stdout,
-		Stderr: &stderr,
-	}
+func (s *S) TestVolumeListGroupByPool(c *check.C) {
+	response := `[
+		{"Name":"c-vol","Pool":"zpool","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"a-vol","Pool":"zpool","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"b-vol","Pool":"apool","Plan":{"Name":"ebs"},"TeamOwner":"admin"}
+]`
 	trans := &cmdtest.ConditionalTransport{
-		Transport: cmdtest.Transport{Message: "", Status: http.StatusNoContent},
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
 		CondFunc: func(req *http.Request) bool {
-			return strings.HasSuffix(req.URL.Path, "/volumes/vol") && req.Method == "GET"
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
 		},
 	}
 	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
-	command := &VolumeInfo{}
-	err := command.Run(&ctx, client)
+	var stdout bytes.Buffer
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--group-by", "pool", "--fields", "name,plan"})
+	err := command.Run(&cmd.Context{Stdout: &stdout}, client)
 	c.Assert(err, check.IsNil)
-	result := stdout.String()
-	c.Assert(result, check.Equals, "No volumes available.\n")
+	c.Assert(stdout.String(), check.Equals, `Pool: apool
++-------+------+
+| Name  | Plan |
++-------+------+
+| b-vol | ebs  |
++-------+------+
+
+Pool: zpool
++-------+------+
+| Name  | Plan |
++-------+------+
+| a-vol | nfs  |
++-------+------+
+| c-vol | nfs  |
++-------+------+
+`)
+	stdout.Reset()
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"--group-by", "pool", "--json"})
+	err = command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	var groups map[string][]volumeTypes.Volume
+	err = json.Unmarshal(stdout.Bytes(), &groups)
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 2)
+	c.Assert(groups["apool"], check.HasLen, 1)
+	c.Assert(groups["zpool"], check.HasLen, 2)
+	c.Assert(groups["zpool"][0].Name, check.Equals, "a-vol")
 }
 
-func (s *S) TestVolumePlansListInfo(c *check.C) {
-	c.Assert((&VolumePlansList{}).Info(), check.NotNil)
+func (s *S) TestVolumeListInvalidGroupBy(c *check.C) {
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--group-by", "status"})
+	err := command.Run(&cmd.Context{}, nil)
+	c.Assert(err, check.ErrorMatches, `invalid group "status", valid options are: pool, team, plan`)
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"--group-by", "pool", "-q"})
+	err = command.Run(&cmd.Context{}, nil)
+	c.Assert(err, check.ErrorMatches, `the --group-by flag can't be used with --jsonl, --count, --no-header, --select or -q`)
 }
 
-func (s *S) TestVolumePlansList(c *check.C) {
+func (s *S) TestVolumeListNoHeader(c *check.C) {
 	var stdout, stderr bytes.Buffer
-	response := `{
-	"kubernetes": [{"Name":"nfs","Opts":{"access-modes":"ReadWriteMany","plugin":"nfs"}}, {"Name":"ebs","Opts":{"storage-class":"myebs"}}],
-	"swarm": [{"Name":"nfs","Opts":{"driver":"local", "opt": [{"type": "nfs"}]}}]
-}`
+	response := `[
+		{"Name":"a-vol","Pool":"zpool","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"b-vol","Pool":"apool","Plan":{"Name":"ebs"},"TeamOwner":"admin","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt","Volume":"b-vol"}}]}
+]`
 	ctx := cmd.Context{
 		Args:   []string{},
 		Stdout: &stdout,
@@ -170,363 +192,5793 @@ func (s *S) TestVolumePlansList(c *check.C) {
 	trans := &cmdtest.ConditionalTransport{
 		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
 		CondFunc: func(req *http.Request) bool {
-			return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
 		},
 	}
 	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
-	err := (&VolumePlansList{}).Run(&ctx, client)
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--no-header", "--sort", "pool"})
+	err := command.Run(&ctx, client)
 	c.Assert(err, check.IsNil)
-	result := stdout.String()
-	c.Assert(result, check.Equals, `+------+-------------+-----------------------------+
-| Plan | Provisioner | Opts                        |
-+------+-------------+-----------------------------+
-| ebs  | kubernetes  | storage-class: myebs        |
-+------+-------------+-----------------------------+
-| nfs  | kubernetes  | access-modes: ReadWriteMany |
-|      |             | plugin: nfs                 |
-+------+-------------+-----------------------------+
-| nfs  | swarm       | driver: local               |
-|      |             | opt: [map[type:nfs]]        |
-+------+-------------+-----------------------------+
-`)
+	c.Assert(stdout.String(), check.Equals, "b-vol\tebs\tapool\tadmin\t1\na-vol\tnfs\tzpool\tadmin\t0\n")
 }
 
-func (s *S) TestVolumePlansListEmpty(c *check.C) {
-	var stdout, stderr bytes.Buffer
-	ctx := cmd.Context{
-		Args:   []string{},
-		Stdout: &stdout,
-		Stderr: &stderr,
-	}
+func (s *S) TestVolumeListReverse(c *check.C) {
+	var stdout bytes.Buffer
+	response := `[
+		{"Name":"a-vol","Pool":"zpool","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"b-vol","Pool":"apool","Plan":{"Name":"ebs"},"TeamOwner":"admin"},
+		{"Name":"c-vol","Pool":"zpool","Plan":{"Name":"nfs"},"TeamOwner":"admin"}
+]`
 	trans := &cmdtest.ConditionalTransport{
-		Transport: cmdtest.Transport{Status: http.StatusNoContent},
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
 		CondFunc: func(req *http.Request) bool {
-			return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
 		},
 	}
 	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
-	err := (&VolumePlansList{}).Run(&ctx, client)
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"-q", "--sort", "pool", "--reverse"})
+	err := command.Run(&cmd.Context{Stdout: &stdout}, client)
 	c.Assert(err, check.IsNil)
-	result := stdout.String()
-	c.Assert(result, check.Equals, `+------+-------------+------+
-| Plan | Provisioner | Opts |
-+------+-------------+------+
-`)
+	c.Assert(stdout.String(), check.Equals, "c-vol\na-vol\nb-vol\n")
+	stdout.Reset()
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"--json", "--reverse"})
+	err = command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	var volumes []volumeTypes.Volume
+	err = json.Unmarshal(stdout.Bytes(), &volumes)
+	c.Assert(err, check.IsNil)
+	c.Assert(volumes, check.HasLen, 3)
+	c.Assert(volumes[0].Name, check.Equals, "c-vol")
+	c.Assert(volumes[1].Name, check.Equals, "b-vol")
+	c.Assert(volumes[2].Name, check.Equals, "a-vol")
 }
 
-func (s *S) TestVolumeCreateInfo(c *check.C) {
-	c.Assert((&VolumeCreate{}).Info(), check.NotNil)
+func (s *S) TestVolumeListReverseNoSort(c *check.C) {
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--sort", "none", "--reverse"})
+	err := command.Run(&cmd.Context{}, cmd.NewClient(&http.Client{}, nil, manager))
+	c.Assert(err, check.ErrorMatches, "the --reverse flag can't be used with --sort none")
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"--jsonl", "--reverse"})
+	var stderr bytes.Buffer
+	err = command.Run(&cmd.Context{Stderr: &stderr}, cmd.NewClient(&http.Client{}, nil, manager))
+	c.Assert(err, check.Equals, cmd.ErrAbortCommand)
+	c.Assert(stderr.String(), check.Equals, `{"error":"the --reverse flag can't be used with --jsonl"}`+"\n")
 }
 
-func (s *S) TestVolumeCreate(c *check.C) {
-	var stdout, stderr bytes.Buffer
-	ctx := cmd.Context{
-		Args:   []string{"vol1", "plan1"},
-		Stdout: &stdout,
-		Stderr: &stderr,
+func (s *S) TestVolumeListStreamUnsorted(c *check.C) {
+	response := `[
+		{"Name":"c-vol","Pool":"zpool","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"a-vol","Pool":"apool","Plan":{"Name":"ebs"},"TeamOwner":"admin","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt","Volume":"a-vol"}}]},
+		{"Name":"b-vol","Pool":"zpool","Plan":{"Name":"nfs"},"TeamOwner":"admin"}
+]`
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
 	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	var stdout bytes.Buffer
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"-q", "--sort", "none"})
+	err := command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "c-vol\na-vol\nb-vol\n")
+	stdout.Reset()
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"--no-header", "--sort", "none", "--pool", "zpool", "--limit", "1", "--page", "2"})
+	err = command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "b-vol\tnfs\tzpool\tadmin\t0\n")
+}
+
+func (s *S) TestVolumeListJSONLines(c *check.C) {
+	response := `[
+		{"Name":"b-vol","Pool":"zpool","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"a-vol","Pool":"apool","Plan":{"Name":"ebs"},"TeamOwner":"admin"}
+]`
 	trans := &cmdtest.ConditionalTransport{
-		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
-		CondFunc: func(r *http.Request) bool {
-			r.ParseForm()
-			dec := form.NewDecoder(nil)
-			dec.IgnoreCase(true)
-			dec.IgnoreUnknownKeys(true)
-			dec.UseJSONTags(false)
-			var vol volumeTypes.Volume
-			err := dec.DecodeValues(&vol, r.Form)
-			c.Assert(err, check.IsNil)
-			c.Assert(vol, check.DeepEquals, volumeTypes.Volume{
-				Name:      "vol1",
-				Plan:      volumeTypes.VolumePlan{Name: "plan1"},
-				TeamOwner: "team1",
-				Pool:      "pool1",
-				Opts:      map[string]string{"a": "1", "b": "2"},
-			})
-			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
 		},
 	}
 	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
-	command := &VolumeCreate{}
-	command.Flags().Parse(true, []string{"-t", "team1", "-p", "pool1", "-o", "a=1", "-o", "b=2"})
-	err := command.Run(&ctx, client)
+	var stdout bytes.Buffer
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--jsonl", "--pool", "zpool"})
+	err := command.Run(&cmd.Context{Stdout: &stdout}, client)
 	c.Assert(err, check.IsNil)
-	result := stdout.String()
-	c.Assert(result, check.Equals, "Volume successfully created.\n")
+	lines := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+	c.Assert(lines, check.HasLen, 1)
+	var volume volumeTypes.Volume
+	err = json.Unmarshal([]byte(lines[0]), &volume)
+	c.Assert(err, check.IsNil)
+	c.Assert(volume.Name, check.Equals, "b-vol")
+	c.Assert(volume.Pool, check.Equals, "zpool")
 }
 
-func (s *S) TestVolumeUpdateInfo(c *check.C) {
-	c.Assert((&VolumeUpdate{}).Info(), check.NotNil)
+func (s *S) TestVolumeListJSONAndJSONLines(c *check.C) {
+	var stderr bytes.Buffer
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--json", "--jsonl"})
+	err := command.Run(&cmd.Context{Stderr: &stderr}, nil)
+	c.Assert(err, check.Equals, cmd.ErrAbortCommand)
+	c.Assert(stderr.String(), check.Equals, `{"error":"the --json and --jsonl flags are mutually exclusive"}`+"\n")
 }
 
-func (s *S) TestVolumeUpdate(c *check.C) {
-	var stdout, stderr bytes.Buffer
-	ctx := cmd.Context{
-		Args:   []string{"vol1", "plan1"},
-		Stdout: &stdout,
-		Stderr: &stderr,
-	}
+func (s *S) TestVolumeListConfigOutput(c *check.C) {
+	original := volumeClientConfig
+	volumeClientConfig = func() config.VolumeConfig { return config.VolumeConfig{Output: "jsonl"} }
+	defer func() { volumeClientConfig = original }()
+	response := `[{"Name":"b-vol","Pool":"zpool","Plan":{"Name":"nfs"},"TeamOwner":"admin"}]`
 	trans := &cmdtest.ConditionalTransport{
-		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
-		CondFunc: func(r *http.Request) bool {
-			r.ParseForm()
-			dec := form.NewDecoder(nil)
-			dec.IgnoreCase(true)
-			dec.IgnoreUnknownKeys(true)
-			dec.UseJSONTags(false)
-			var vol volumeTypes.Volume
-			err := dec.DecodeValues(&vol, r.Form)
-			c.Assert(err, check.IsNil)
-			c.Assert(vol, check.DeepEquals, volumeTypes.Volume{
-				Name:      "vol1",
-				Plan:      volumeTypes.VolumePlan{Name: "plan1"},
-				TeamOwner: "team1",
-				Pool:      "pool1",
-				Opts:      map[string]string{"a": "1", "b": "2"},
-			})
-			return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "POST"
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
 		},
 	}
 	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
-	command := &VolumeUpdate{}
-	command.Flags().Parse(true, []string{"-t", "team1", "-p", "pool1", "-o", "a=1", "-o", "b=2"})
-	err := command.Run(&ctx, client)
+	var stdout bytes.Buffer
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&cmd.Context{Stdout: &stdout}, client)
 	c.Assert(err, check.IsNil)
-	result := stdout.String()
-	c.Assert(result, check.Equals, "Volume successfully updated.\n")
+	var volume volumeTypes.Volume
+	err = json.Unmarshal(stdout.Bytes(), &volume)
+	c.Assert(err, check.IsNil)
+	c.Assert(volume.Name, check.Equals, "b-vol")
+	stdout.Reset()
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"-q"})
+	err = command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "b-vol\n")
 }
 
-func (s *S) TestVolumeDeleteInfo(c *check.C) {
-	c.Assert((&VolumeDelete{}).Info(), check.NotNil)
+func (s *S) TestVolumeListInvalidConfigOutput(c *check.C) {
+	original := volumeClientConfig
+	volumeClientConfig = func() config.VolumeConfig { return config.VolumeConfig{Output: "yaml"} }
+	defer func() { volumeClientConfig = original }()
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&cmd.Context{}, nil)
+	c.Assert(err, check.ErrorMatches, `invalid volume.output "yaml" in the client config, valid options are: table, json, jsonl`)
 }
 
-func (s *S) TestVolumeDelete(c *check.C) {
+func (s *S) TestVolumeListWide(c *check.C) {
 	var stdout, stderr bytes.Buffer
+	volumes := `[
+		{"Name":"vol1","Pool":"kubepool","Plan":{"Name":"ebs"},"TeamOwner":"admin","Opts":{"capacity":"10Gi"}},
+		{"Name":"vol2","Pool":"kubepool","Plan":{"Name":"nfs","Opts":{"size":"1Gi"}},"TeamOwner":"admin"},
+		{"Name":"vol3","Pool":"kubepool","Plan":{"Name":"custom"},"TeamOwner":"admin"}
+]`
+	plans := `{"kubernetes": [{"Name":"ebs"}, {"Name":"nfs"}]}`
 	ctx := cmd.Context{
-		Args:   []string{"vol1"},
+		Args:   []string{},
 		Stdout: &stdout,
 		Stderr: &stderr,
 	}
-	trans := &cmdtest.ConditionalTransport{
-		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
-		CondFunc: func(r *http.Request) bool {
-			return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "DELETE"
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: volumes, Status: http.StatusOK},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: plans, Status: http.StatusOK},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+				},
+			},
 		},
 	}
 	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
-	command := &VolumeDelete{}
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--wide"})
 	err := command.Run(&ctx, client)
 	c.Assert(err, check.IsNil)
-	result := stdout.String()
-	c.Assert(result, check.Equals, "Volume successfully deleted.\n")
-}
-
-func (s *S) TestVolumeBindInfo(c *check.C) {
-	c.Assert((&VolumeBind{}).Info(), check.NotNil)
+	c.Assert(stdout.String(), check.Equals, `+------+--------+----------+-------+-------+-------------+----------+
+| Name | Plan   | Pool     | Team  | Binds | Provisioner | Capacity |
++------+--------+----------+-------+-------+-------------+----------+
+| vol1 | ebs    | kubepool | admin | 0     | kubernetes  | 10Gi     |
++------+--------+----------+-------+-------+-------------+----------+
+| vol2 | nfs    | kubepool | admin | 0     | kubernetes  | 1Gi      |
++------+--------+----------+-------+-------+-------------+----------+
+| vol3 | custom | kubepool | admin | 0     | -           | -        |
++------+--------+----------+-------+-------+-------------+----------+
+`)
 }
 
-func (s *S) TestVolumeBind(c *check.C) {
+func (s *S) TestVolumeListShowMountPoints(c *check.C) {
 	var stdout, stderr bytes.Buffer
+	response := `[
+		{"Name":"a-vol","Pool":"zpool","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"b-vol","Pool":"apool","Plan":{"Name":"ebs"},"TeamOwner":"admin","Binds":[{"ID":{"App":"app1","MountPoint":"/mnt","Volume":"b-vol"}},{"ID":{"App":"app2","MountPoint":"/data","Volume":"b-vol"}}]}
+]`
 	ctx := cmd.Context{
-		Args:   []string{"vol1", "/mnt"},
+		Args:   []string{},
 		Stdout: &stdout,
 		Stderr: &stderr,
 	}
 	trans := &cmdtest.ConditionalTransport{
-		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
-		CondFunc: func(r *http.Request) bool {
-			r.ParseForm()
-			c.Assert(r.FormValue("App"), check.Equals, "myapp")
-			c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt")
-			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
 		},
 	}
 	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
-	command := &VolumeBind{}
-	command.Flags().Parse(true, []string{"-a", "myapp"})
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--show-mountpoints", "--fields", "name,binds"})
 	err := command.Run(&ctx, client)
 	c.Assert(err, check.IsNil)
-	result := stdout.String()
-	c.Assert(result, check.Equals, "Volume successfully bound.\n")
+	c.Assert(stdout.String(), check.Equals, `+-------+-------+--------------+
+| Name  | Binds | Mount Points |
++-------+-------+--------------+
+| a-vol | 0     | -            |
++-------+-------+--------------+
+| b-vol | 2     | app1:/mnt    |
+|       |       | app2:/data   |
++-------+-------+--------------+
+`)
+	stdout.Reset()
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"--show-mountpoints", "--no-header", "--bound"})
+	err = command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "b-vol\tebs\tapool\tadmin\t2\tapp1:/mnt,app2:/data\n")
 }
 
-func (s *S) TestVolumeBindNoRestart(c *check.C) {
+func (s *S) TestVolumeListInvalidSort(c *check.C) {
 	var stdout, stderr bytes.Buffer
 	ctx := cmd.Context{
-		Args:   []string{"vol1", "/mnt"},
+		Args:   []string{},
 		Stdout: &stdout,
 		Stderr: &stderr,
 	}
-	trans := &cmdtest.ConditionalTransport{
-		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
-		CondFunc: func(r *http.Request) bool {
-			r.ParseForm()
-			c.Assert(r.FormValue("App"), check.Equals, "myapp")
-			c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt")
-			c.Assert(r.FormValue("NoRestart"), check.Equals, "true")
-			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
-		},
-	}
-	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
-	command := &VolumeBind{}
-	command.Flags().Parse(true, []string{"-a", "myapp", "--no-restart"})
-	err := command.Run(&ctx, client)
-	c.Assert(err, check.IsNil)
-	result := stdout.String()
-	c.Assert(result, check.Equals, "Volume successfully bound.\n")
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--sort", "size"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, `invalid sort column "size", valid options are: name, plan, pool, team, none`)
 }
 
-func (s *S) TestVolumeBindRO(c *check.C) {
+func (s *S) TestVolumeListFields(c *check.C) {
 	var stdout, stderr bytes.Buffer
+	response := `[
+		{"Name":"a-vol","Pool":"zpool","Plan":{"Name":"nfs"},"TeamOwner":"admin","Opts":{"capacity":"1Gi"}},
+		{"Name":"b-vol","Pool":"apool","Plan":{"Name":"ebs"},"TeamOwner":"admin","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt","Volume":"b-vol"}}]}
+]`
 	ctx := cmd.Context{
-		Args:   []string{"vol1", "/mnt"},
+		Args:   []string{},
 		Stdout: &stdout,
 		Stderr: &stderr,
 	}
 	trans := &cmdtest.ConditionalTransport{
-		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
-		CondFunc: func(r *http.Request) bool {
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--fields", "binds, name,capacity", "--sort", "pool"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `+-------+-------+----------+
+| Binds | Name  | Capacity |
++-------+-------+----------+
+| 1     | b-vol | -        |
++-------+-------+----------+
+| 0     | a-vol | 1Gi      |
++-------+-------+----------+
+`)
+}
+
+func (s *S) TestVolumeListSelect(c *check.C) {
+	response := `[
+		{"Name":"b-vol","Pool":"zpool","Plan":{"Name":"nfs","Opts":{"capacity":"2Gi"}},"TeamOwner":"admin"},
+		{"Name":"c-vol","Pool":"zpool","Plan":{"Name":"ebs","Opts":{"replicas":3}},"TeamOwner":"admin"},
+		{"Name":"a-vol","Pool":"apool","Plan":{"Name":"nfs","Opts":{"capacity":"1Gi"}},"TeamOwner":"admin"}
+]`
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	var stdout bytes.Buffer
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--select", ".Plan.Opts.capacity"})
+	err := command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "1Gi\n2Gi\n\n")
+	stdout.Reset()
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"--select", ".plan.opts", "--sort", "none"})
+	err = command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `{"capacity":"2Gi"}`+"\n"+`{"replicas":3}`+"\n"+`{"capacity":"1Gi"}`+"\n")
+}
+
+func (s *S) TestVolumeListTemplate(c *check.C) {
+	response := `[
+		{"Name":"b-vol","Pool":"zpool","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"a-vol","Pool":"apool","Plan":{"Name":"ebs"},"TeamOwner":"admin"},
+		{"Name":"c-vol","Pool":"zpool","Plan":{"Name":"nfs"},"TeamOwner":"other"}
+]`
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	var stdout bytes.Buffer
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--template", "{{.Name}} {{.Pool}}", "--team", "admin"})
+	err := command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "a-vol apool\nb-vol zpool\n")
+	stdout.Reset()
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"--template", "{{.Name}},", "--sort", "none"})
+	err = command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "b-vol,\na-vol,\nc-vol,\n")
+}
+
+func (s *S) TestVolumeListInvalidTemplate(c *check.C) {
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--template", "{{.Name"})
+	err := command.Run(&cmd.Context{}, nil)
+	c.Assert(err, check.ErrorMatches, `invalid --template: .*`)
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"--template", "{{.Name}}", "--count"})
+	err = command.Run(&cmd.Context{}, nil)
+	c.Assert(err, check.ErrorMatches, `the --template flag can't be used with --json, --jsonl, --count, --select, --group-by or -q`)
+}
+
+func (s *S) TestVolumeListInvalidSelect(c *check.C) {
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--select", "Plan..Name"})
+	err := command.Run(&cmd.Context{}, nil)
+	c.Assert(err, check.ErrorMatches, `invalid --select path "Plan..Name", it must be a dotted path like .Plan.Opts.capacity`)
+	var stderr bytes.Buffer
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"--select", ".Name", "--json"})
+	err = command.Run(&cmd.Context{Stderr: &stderr}, nil)
+	c.Assert(err, check.Equals, cmd.ErrAbortCommand)
+	c.Assert(stderr.String(), check.Equals, `{"error":"the --select flag can't be used with --json, --jsonl, --count or -q"}`+"\n")
+}
+
+func (s *S) TestVolumeListInvalidField(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--fields", "name,size"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, `invalid field "size", valid options are: binds, capacity, mountpoints, name, plan, pool, provisioner, team`)
+}
+
+func (s *S) TestVolumeListReportsIgnoredFilters(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `[
+		{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"vol2","Pool":"pool2","Plan":{"Name":"nfs"},"TeamOwner":"admin"}
+]`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			c.Assert(req.URL.Query().Get("pool"), check.Equals, "pool1")
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	client.Verbosity = 1
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"-q", "--pool", "pool1"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "vol1\n")
+	c.Assert(stderr.String(), check.Matches, `(?s).*The server returned 1 volume\(s\) not matching the filters, they were filtered out on the client side.*`)
+}
+
+func (s *S) TestVolumeListClientFilterOnly(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `[
+		{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"vol2","Pool":"pool2","Plan":{"Name":"nfs"},"TeamOwner":"admin"}
+]`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			c.Assert(req.URL.RawQuery, check.Equals, "")
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	client.Verbosity = 1
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"-q", "--pool", "pool2", "--client-filter-only"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "vol2\n")
+	c.Assert(stderr.String(), check.Not(check.Matches), `(?s).*filtered out on the client side.*`)
+}
+
+func (s *S) TestVolumeListOlderThan(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	old := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	response := `[
+		{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin","CreatedAt":"` + old + `"},
+		{"Name":"vol2","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin","CreatedAt":"` + recent + `"}
+]`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"-q", "--older-than", "24h"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "vol1\n")
+	c.Assert(stderr.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeListOlderThanWithoutCreationTime(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `[{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin"}]`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"-q", "--older-than", "24h"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "vol1\n")
+	c.Assert(stderr.String(), check.Equals, "Note: the server doesn't expose the creation time of some volumes, they were kept regardless of --older-than.\n")
+}
+
+func (s *S) TestVolumeListCreatedBy(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `[
+		{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin","CreatedBy":"alice@example.com"},
+		{"Name":"vol2","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin","CreatedBy":"bob@example.com"},
+		{"Name":"vol3","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin"}
+]`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"-q", "--created-by", "alice@example.com"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "vol1\n")
+	c.Assert(stderr.String(), check.Equals, "Note: the server doesn't expose the creator of some volumes, they were left out of the list. Use --enrich-owner to look it up in their events.\n")
+}
+
+func (s *S) TestVolumeListCreatedByEnrichOwner(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `[
+		{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"vol2","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin"}
+]`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{
+					Message: `[
+	{"Kind":{"Type":"permission","Name":"volume.bind"},"Owner":{"Type":"user","Name":"bob@example.com"}},
+	{"Kind":{"Type":"permission","Name":"volume.create"},"Owner":{"Type":"user","Name":"alice@example.com"}}
+]`,
+					Status: http.StatusOK,
+				},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/events") && req.URL.Query().Get("target.value") == "vol1"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusNoContent},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/events") && req.URL.Query().Get("target.value") == "vol2"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"-q", "--created-by", "alice@example.com", "--enrich-owner"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "vol1\n")
+	c.Assert(stderr.String(), check.Equals, "Note: the creator of some volumes couldn't be found in their events, they were left out of the list.\n")
+}
+
+func (s *S) TestVolumeListEnrichOwnerAfterOtherFilters(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `[
+		{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"vol2","Pool":"pool2","Plan":{"Name":"nfs"},"TeamOwner":"admin"}
+]`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	var lookups []string
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{
+					Message: `[{"Kind":{"Type":"permission","Name":"volume.create"},"Owner":{"Type":"user","Name":"alice@example.com"}}]`,
+					Status:  http.StatusOK,
+				},
+				CondFunc: func(req *http.Request) bool {
+					lookups = append(lookups, req.URL.Query().Get("target.value"))
+					return strings.HasSuffix(req.URL.Path, "/events")
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"-q", "-o", "pool1", "--created-by", "alice@example.com", "--enrich-owner"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "vol1\n")
+	c.Assert(lookups, check.DeepEquals, []string{"vol1"})
+}
+
+func (s *S) TestVolumeListEnrichOwnerWithoutCreatedBy(c *check.C) {
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--enrich-owner"})
+	err := command.Run(&cmd.Context{}, cmd.NewClient(&http.Client{}, nil, manager))
+	c.Assert(err, check.ErrorMatches, "the --enrich-owner flag can only be used with --created-by")
+}
+
+func (s *S) TestVolumeListFilterByTag(c *check.C) {
+	response := `[
+		{"Name":"a-vol","Opts":{"tsuru-tag-team":"payments","tsuru-tag-env":"prod"}},
+		{"Name":"b-vol","Opts":{"tsuru-tag-team":"payments","tsuru-tag-env":"dev"}},
+		{"Name":"c-vol","Opts":{"team":"payments","env":"prod"}}
+]`
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	var stdout bytes.Buffer
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"-q", "--tag", "team=payments", "--tag", "env=prod"})
+	err := command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "a-vol\n")
+}
+
+func (s *S) TestVolumeListBoundAndUnbound(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--bound", "--unbound"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, "the --bound and --unbound flags are mutually exclusive")
+}
+
+func (s *S) TestVolumeListInvalidNameRegex(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--name-regex", "vol("})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, `invalid --name-regex pattern: .*`)
+}
+
+func (s *S) TestVolumeListEmpty(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusNoContent},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	err := (&VolumeList{}).Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	result := stdout.String()
+	c.Assert(result, check.Equals, "No volumes available.\n")
+}
+
+func (s *S) TestVolumeListPaginated(c *check.C) {
+	response := `[
+		{"Name":"vol3","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin"},
+		{"Name":"vol2","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin"}
+]`
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--limit", "2", "--page", "2"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `+------+------+-------+-------+-------+
+| Name | Plan | Pool  | Team  | Binds |
++------+------+-------+-------+-------+
+| vol3 | nfs  | pool1 | admin | 0     |
++------+------+-------+-------+-------+
+Showing 3-3 of 3
+`)
+	stdout.Reset()
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"--limit", "2", "--no-header"})
+	err = command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "vol1\tnfs\tpool1\tadmin\t0\nvol2\tnfs\tpool1\tadmin\t0\n")
+	stdout.Reset()
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"--limit", "2", "--page", "2", "-q"})
+	err = command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "vol3\n")
+	stdout.Reset()
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"--limit", "2", "--json"})
+	err = command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	var volumes []volumeTypes.Volume
+	err = json.Unmarshal(stdout.Bytes(), &volumes)
+	c.Assert(err, check.IsNil)
+	c.Assert(volumes, check.HasLen, 2)
+	c.Assert(volumes[0].Name, check.Equals, "vol1")
+	c.Assert(volumes[1].Name, check.Equals, "vol2")
+}
+
+func (s *S) TestVolumeListInvalidPage(c *check.C) {
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--limit", "2", "--page", "0"})
+	err := command.Run(&cmd.Context{}, nil)
+	c.Assert(err, check.ErrorMatches, "the --page flag must be greater than zero")
+}
+
+func (s *S) TestVolumeListColorVolumeRow(c *check.C) {
+	bound := colorVolumeRow(tablecli.Row{"vol1", "nfs", "pool1", "admin", "2"}, true)
+	c.Assert(bound, check.DeepEquals, tablecli.Row{
+		cmd.Colorfy("vol1", "green", "", ""),
+		cmd.Colorfy("nfs", "green", "", ""),
+		cmd.Colorfy("pool1", "green", "", ""),
+		cmd.Colorfy("admin", "green", "", ""),
+		cmd.Colorfy("2", "green", "", ""),
+	})
+	unbound := colorVolumeRow(tablecli.Row{"vol2", "nfs", "pool1", "admin", "0"}, false)
+	c.Assert(unbound[0], check.Equals, cmd.Colorfy("vol2", "yellow", "", ""))
+}
+
+func (s *S) TestVolumeListUseColors(c *check.C) {
+	var stdout bytes.Buffer
+	command := &VolumeList{}
+	c.Assert(command.useColors(&cmd.Context{Stdout: &stdout}), check.Equals, false)
+	command.noColor = true
+	c.Assert(command.useColors(&cmd.Context{Stdout: os.Stdout}), check.Equals, false)
+	command.noColor = false
+	os.Setenv("NO_COLOR", "1")
+	defer os.Unsetenv("NO_COLOR")
+	c.Assert(command.useColors(&cmd.Context{Stdout: os.Stdout}), check.Equals, false)
+}
+
+func (s *S) TestVolumeListCount(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `[
+		{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt","Volume":"vol1"}}]},
+		{"Name":"vol2","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin","Binds":null},
+		{"Name":"vol3","Pool":"pool2","Plan":{"Name":"nfs"},"TeamOwner":"admin","Binds":null}
+]`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--count", "--unbound"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "2\n")
+}
+
+func (s *S) TestVolumeListExists(c *check.C) {
+	response := `[
+		{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt","Volume":"vol1"}}]},
+		{"Name":"vol2","Pool":"pool2","Plan":{"Name":"nfs"},"TeamOwner":"admin","Binds":null}
+]`
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{Stdout: &stdout, Stderr: &stderr}
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--exists", "--unbound"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "")
+	command = &VolumeList{}
+	command.Flags().Parse(true, []string{"--exists", "--unbound", "--pool", "pool1", "--client-filter-only"})
+	err = command.Run(&ctx, client)
+	c.Assert(err, check.Equals, cmd.ErrAbortCommand)
+	c.Assert(stdout.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeListExistsNoVolumes(c *check.C) {
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Status: http.StatusNoContent},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{Stdout: &stdout, Stderr: &stderr}
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--exists"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.Equals, cmd.ErrAbortCommand)
+	c.Assert(stdout.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeListExistsConflictingFlags(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{Stdout: &stdout, Stderr: &stderr}
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--exists", "--json"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.Equals, cmd.ErrAbortCommand)
+	c.Assert(stderr.String(), check.Matches, `\{"error":"the --exists flag can't be used with .*"\}\n`)
+}
+
+func (s *S) TestVolumeListCountEmpty(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusNoContent},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--count"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "0\n")
+}
+
+func (s *S) TestVolumeListRetriesServerErrors(c *check.C) {
+	volumeRetryBackoff = 0
+	defer func() { volumeRetryBackoff = 500 * time.Millisecond }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	cond := func(req *http.Request) bool {
+		return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{Transport: cmdtest.Transport{Message: "bad gateway", Status: http.StatusBadGateway}, CondFunc: cond},
+			{Transport: cmdtest.Transport{Message: "unavailable", Status: http.StatusServiceUnavailable}, CondFunc: cond},
+			{Transport: cmdtest.Transport{Message: `[{"Name":"vol1"}]`, Status: http.StatusOK}, CondFunc: cond},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"-q"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "vol1\n")
+	c.Assert(stderr.String(), check.Matches, `(?s)Request to .*/volumes failed \(502 Bad Gateway\), retrying in 0s \(attempt 1 of 2\)\.\.\.
+Request to .*/volumes failed \(503 Service Unavailable\), retrying in 0s \(attempt 2 of 2\)\.\.\.
+`)
+}
+
+func (s *S) TestVolumeListDoesNotRetryClientErrors(c *check.C) {
+	volumeRetryBackoff = 0
+	defer func() { volumeRetryBackoff = 500 * time.Millisecond }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: "forbidden", Status: http.StatusForbidden},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, "forbidden")
+	c.Assert(stderr.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeRetryTransportCancelled(c *check.C) {
+	var calls int
+	base := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "unavailable", Status: http.StatusServiceUnavailable},
+		CondFunc: func(req *http.Request) bool {
+			calls++
+			return true
+		},
+	}
+	var stderr bytes.Buffer
+	transport := &retryTransport{base: base, retries: 2, stderr: &stderr}
+	reqCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := http.NewRequestWithContext(reqCtx, "GET", "http://localhost/volumes", nil)
+	c.Assert(err, check.IsNil)
+	resp, err := transport.RoundTrip(req)
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.StatusCode, check.Equals, http.StatusServiceUnavailable)
+	c.Assert(calls, check.Equals, 1)
+	c.Assert(stderr.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeBindListInfo(c *check.C) {
+	c.Assert((&VolumeBindList{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeBindList(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `[
+		{"Name":"vol1","Pool":"kubepool","Plan":{"Name":"nfs"},"TeamOwner":"admin","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt1","Volume":"vol1"},"ReadOnly":true},{"ID":{"App":"otherapp","MountPoint":"/data","Volume":"vol1"},"ReadOnly":false}]},
+		{"Name":"vol2","Pool":"otherpool","Plan":{"Name":"nfs"},"TeamOwner":"admin","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt2","Volume":"vol2"},"ReadOnly":false}]},
+		{"Name":"vol3","Pool":"kubepool","Plan":{"Name":"nfs"},"TeamOwner":"admin","Binds":null}
+]`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	err := (&VolumeBindList{}).Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `+----------+--------+------------+------+-----------+
+| App      | Volume | MountPoint | Mode | Pool      |
++----------+--------+------------+------+-----------+
+| myapp    | vol1   | /mnt1      | ro   | kubepool  |
++----------+--------+------------+------+-----------+
+| myapp    | vol2   | /mnt2      | rw   | otherpool |
++----------+--------+------------+------+-----------+
+| otherapp | vol1   | /data      | rw   | kubepool  |
++----------+--------+------------+------+-----------+
+`)
+}
+
+func (s *S) TestVolumeBindListFilterByAppJSON(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `[
+		{"Name":"vol1","Pool":"kubepool","Plan":{"Name":"nfs"},"TeamOwner":"admin","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt1","Volume":"vol1"},"ReadOnly":true},{"ID":{"App":"otherapp","MountPoint":"/data","Volume":"vol1"},"ReadOnly":false}]}
+]`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBindList{}
+	command.Flags().Parse(true, []string{"-a", "otherapp", "--json"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	var binds []volumeBindEntry
+	err = json.Unmarshal(stdout.Bytes(), &binds)
+	c.Assert(err, check.IsNil)
+	c.Assert(binds, check.DeepEquals, []volumeBindEntry{
+		{App: "otherapp", Volume: "vol1", MountPoint: "/data", Pool: "kubepool"},
+	})
+}
+
+func (s *S) TestVolumeInfoInfo(c *check.C) {
+	c.Assert((&VolumeInfo{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeInfo(c *check.C) {
+	fsystem = &fstest.RecordingFs{}
+	defer func() { fsystem = nil }()
+	var stdout, stderr bytes.Buffer
+	response := `
+		{"Name":"vol1","Pool":"kubepool","Plan":{"Name":"nfs","Opts":{"access-modes":"ReadWriteMany","plugin":"nfs","read-only":false,"replicas":3,"mount-options":{"vers":"4","hard":true}}},"TeamOwner":"admin","Status":"","Binds":[{"ID":{"App":"myapp","MountPoint":"/mymnt","Volume":"vag-nfs"},"ReadOnly":false},{"ID":{"App":"myapp","MountPoint":"/mymnt1","Volume":"vag-nfs"},"ReadOnly":false}],"Opts":{"capacity":"1Gi","path":"/home/vagrant/nfstest","server":"192.168.50.4"}}`
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumes/vol1") && req.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: `{"kubernetes": [{"Name":"nfs"}]}`, Status: http.StatusOK},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeInfo{}
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	result := stdout.String()
+	c.Assert(result, check.Equals, `Name: vol1
+Plan: nfs
+Provisioner: kubernetes
+Pool: kubepool
+Team: admin
+Capacity: 1Gi
+
+Binds:
++-------+------------+------+
+| App   | MountPoint | Mode |
++-------+------------+------+
+| myapp | /mymnt     | rw   |
++-------+------------+------+
+| myapp | /mymnt1    | rw   |
++-------+------------+------+
+(0 read-only, 2 read-write)
+Apps: myapp
+Summary: 1 app(s), 2 mount point(s)
+
+Plan Opts:
++---------------+--------------------------+
+| Key           | Value                    |
++---------------+--------------------------+
+| access-modes  | "ReadWriteMany"          |
++---------------+--------------------------+
+| mount-options | {"hard":true,"vers":"4"} |
++---------------+--------------------------+
+| plugin        | "nfs"                    |
++---------------+--------------------------+
+| read-only     | false                    |
++---------------+--------------------------+
+| replicas      | 3                        |
++---------------+--------------------------+
+
+Opts:
++----------+-------------------------+
+| Key      | Value                   |
++----------+-------------------------+
+| capacity | "1Gi"                   |
++----------+-------------------------+
+| path     | "/home/vagrant/nfstest" |
++----------+-------------------------+
+| server   | "192.168.50.4"          |
++----------+-------------------------+
+`)
+}
+
+func (s *S) TestVolumeInfoWatch(c *check.C) {
+	interrupt := make(chan os.Signal, 1)
+	interrupt <- os.Interrupt
+	var stopped bool
+	original := volumeWatchInterrupt
+	volumeWatchInterrupt = func() (<-chan os.Signal, func()) {
+		return interrupt, func() { stopped = true }
+	}
+	defer func() { volumeWatchInterrupt = original }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{
+			Message: `{"Name":"vol1","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt","Volume":"vol1"}}]}`,
+			Status:  http.StatusOK,
+		},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes/vol1") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeInfo{}
+	command.Flags().Parse(true, []string{"--watch", "--apps-only", "--interval", "1h"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stopped, check.Equals, true)
+	c.Assert(stdout.String(), check.Matches, `Every 1h0m0s: volume info vol1\t.+\n\nmyapp\n`)
+}
+
+func (s *S) TestVolumeInfoWatchInvalidInterval(c *check.C) {
+	command := &VolumeInfo{}
+	command.Flags().Parse(true, []string{"--watch", "--interval", "0s"})
+	err := command.Run(&cmd.Context{Args: []string{"vol1"}}, cmd.NewClient(&http.Client{}, nil, manager))
+	c.Assert(err, check.ErrorMatches, "the --interval flag must be greater than zero")
+}
+
+func (s *S) TestVolumeInfoAppsOnly(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `
+		{"Name":"vol1","Pool":"kubepool","Plan":{"Name":"nfs"},"TeamOwner":"admin","Binds":[{"ID":{"App":"myapp","MountPoint":"/mymnt","Volume":"vol1"}},{"ID":{"App":"otherapp","MountPoint":"/data","Volume":"vol1"}},{"ID":{"App":"myapp","MountPoint":"/logs","Volume":"vol1"}}]}`
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes/vol1") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeInfo{}
+	command.Flags().Parse(true, []string{"--apps-only"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "myapp\notherapp\n")
+}
+
+func (s *S) TestVolumeInfoTemplate(c *check.C) {
+	var stdout bytes.Buffer
+	response := `{"Name":"vol1","Pool":"kubepool","Plan":{"Name":"nfs"},"TeamOwner":"admin","Binds":[{"ID":{"App":"myapp","MountPoint":"/mymnt","Volume":"vol1"},"SubPath":"logs"}]}`
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes/vol1") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeInfo{}
+	command.Flags().Parse(true, []string{"--template", "{{.Plan.Name}} {{range .Binds}}{{.ID.App}}:{{.SubPath}}{{end}}"})
+	err := command.Run(&cmd.Context{Args: []string{"vol1"}, Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "nfs myapp:logs\n")
+}
+
+func (s *S) TestVolumeInfoInvalidTemplate(c *check.C) {
+	command := &VolumeInfo{}
+	command.Flags().Parse(true, []string{"--template", "{{.Plan.Name"})
+	err := command.Run(&cmd.Context{Args: []string{"vol1"}}, nil)
+	c.Assert(err, check.ErrorMatches, `invalid --template: .*`)
+	var stderr bytes.Buffer
+	command = &VolumeInfo{}
+	command.Flags().Parse(true, []string{"--template", "{{.Name}}", "--json"})
+	err = command.Run(&cmd.Context{Args: []string{"vol1"}, Stderr: &stderr}, nil)
+	c.Assert(err, check.Equals, cmd.ErrAbortCommand)
+	c.Assert(stderr.String(), check.Equals, `{"error":"the --template flag can't be used with --json or --apps-only"}`+"\n")
+}
+
+func (s *S) TestVolumeInfoWithSubPath(c *check.C) {
+	fsystem = &fstest.RecordingFs{}
+	defer func() { fsystem = nil }()
+	var stdout, stderr bytes.Buffer
+	response := `
+		{"Name":"vol1","Pool":"kubepool","Plan":{"Name":"nfs"},"TeamOwner":"admin","Binds":[{"ID":{"App":"myapp","MountPoint":"/mymnt","Volume":"vol1"},"ReadOnly":true,"SubPath":"logs"},{"ID":{"App":"otherapp","MountPoint":"/data","Volume":"vol1"},"ReadOnly":false}]}`
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumes/vol1") && req.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Status: http.StatusNoContent},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeInfo{}
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Name: vol1
+Plan: nfs
+Pool: kubepool
+Team: admin
+
+Binds:
++----------+------------+------+---------+
+| App      | MountPoint | Mode | SubPath |
++----------+------------+------+---------+
+| myapp    | /mymnt     | ro   | logs    |
++----------+------------+------+---------+
+| otherapp | /data      | rw   |         |
++----------+------------+------+---------+
+(1 read-only, 1 read-write)
+Apps: myapp, otherapp
+Summary: 2 app(s), 2 mount point(s)
+
+Plan Opts:
++-----+-------+
+| Key | Value |
++-----+-------+
+
+Opts:
++-----+-------+
+| Key | Value |
++-----+-------+
+`)
+}
+
+func (s *S) TestVolumeInfoWithPropagation(c *check.C) {
+	fsystem = &fstest.RecordingFs{}
+	defer func() { fsystem = nil }()
+	var stdout bytes.Buffer
+	response := `{"Name":"vol1","Pool":"kubepool","Plan":{"Name":"nfs"},"TeamOwner":"admin","Binds":[{"ID":{"App":"myapp","MountPoint":"/mymnt","Volume":"vol1"},"Propagation":"HostToContainer"},{"ID":{"App":"otherapp","MountPoint":"/data","Volume":"vol1"}}]}`
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumes/vol1") && req.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Status: http.StatusNoContent},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	err := (&VolumeInfo{}).Run(&cmd.Context{Args: []string{"vol1"}, Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Matches, `(?s).*
+\| App      \| MountPoint \| Mode \| Propagation     \|
+.*
+\| myapp    \| /mymnt     \| rw   \| HostToContainer \|
+.*
+\| otherapp \| /data      \| rw   \|                 \|
+.*`)
+}
+
+func (s *S) TestVolumeInfoPlansForbidden(c *check.C) {
+	fsystem = &fstest.RecordingFs{}
+	defer func() { fsystem = nil }()
+	var stdout bytes.Buffer
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: `{"Name":"vol1","Pool":"kubepool","Plan":{"Name":"nfs"},"TeamOwner":"admin"}`, Status: http.StatusOK},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumes/vol1") && req.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "forbidden", Status: http.StatusForbidden},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	err := (&VolumeInfo{}).Run(&cmd.Context{Args: []string{"vol1"}, Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Matches, "Name: vol1\nPlan: nfs\nPool: kubepool\nTeam: admin\n\nBinds:\n(?s).*")
+}
+
+func (s *S) TestVolumeInfoWithTags(c *check.C) {
+	fsystem = &fstest.RecordingFs{}
+	defer func() { fsystem = nil }()
+	var stdout bytes.Buffer
+	response := `{"Name":"vol1","Pool":"kubepool","Plan":{"Name":"nfs"},"TeamOwner":"admin","Opts":{"capacity":"1Gi","tsuru-tag-team":"payments"}}`
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumes/vol1") && req.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Status: http.StatusNoContent},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeInfo{}
+	err := command.Run(&cmd.Context{Args: []string{"vol1"}, Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.HasSuffix(stdout.String(), `
+Opts:
++----------+-------+
+| Key      | Value |
++----------+-------+
+| capacity | "1Gi" |
++----------+-------+
+
+Tags:
++------+----------+
+| Key  | Value    |
++------+----------+
+| team | payments |
++------+----------+
+`), check.Equals, true, check.Commentf("Got: %s", stdout.String()))
+}
+
+func (s *S) TestVolumeInfoJSONOpts(c *check.C) {
+	fsystem = &fstest.RecordingFs{}
+	defer func() { fsystem = nil }()
+	var stdout bytes.Buffer
+	response := `{"Name":"vol1","Pool":"kubepool","Plan":{"Name":"nfs"},"TeamOwner":"admin","Opts":{"capacity":"1Gi","selector":"{\"zone\":\"us-east-1a\",\"tier\":\"gold\"}"}}`
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumes/vol1") && req.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Status: http.StatusNoContent},
+				CondFunc: func(req *http.Request) bool {
+					return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeInfo{}
+	err := command.Run(&cmd.Context{Args: []string{"vol1"}, Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.HasSuffix(stdout.String(), `
+Opts:
++----------+-------------------------+
+| Key      | Value                   |
++----------+-------------------------+
+| capacity | "1Gi"                   |
++----------+-------------------------+
+| selector | {                       |
+|          |   "zone": "us-east-1a", |
+|          |   "tier": "gold"        |
+|          | }                       |
++----------+-------------------------+
+`), check.Equals, true, check.Commentf("Got: %s", stdout.String()))
+}
+
+func (s *S) TestVolumeInfoShowYAMLSpec(c *check.C) {
+	var stdout bytes.Buffer
+	response := `{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs","Opts":{"driver":"nfs"}},"TeamOwner":"admin","Status":"ready","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt","Volume":"vol1"}}],"Opts":{"capacity":"1Gi","tsuru-tag-team":"payments"}}`
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes/vol1") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeInfo{}
+	command.Flags().Parse(true, []string{"--show-yaml-spec"})
+	err := command.Run(&cmd.Context{Args: []string{"vol1"}, Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `- Name: vol1
+  Opts:
+    capacity: 1Gi
+    tsuru-tag-team: payments
+  Plan:
+    Name: nfs
+  Pool: pool1
+  TeamOwner: admin
+`)
+}
+
+func (s *S) TestVolumeInfoShowYAMLSpecWithJSON(c *check.C) {
+	var stderr bytes.Buffer
+	command := &VolumeInfo{}
+	command.Flags().Parse(true, []string{"--show-yaml-spec", "--json"})
+	err := command.Run(&cmd.Context{Args: []string{"vol1"}, Stderr: &stderr}, nil)
+	c.Assert(err, check.Equals, cmd.ErrAbortCommand)
+	c.Assert(stderr.String(), check.Equals, `{"error":"the --show-yaml-spec flag can't be used with --json, --apps-only or --template"}`+"\n")
+}
+
+func (s *S) TestVolumeInfoEmpty(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusNoContent},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes/vol") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeInfo{}
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	result := stdout.String()
+	c.Assert(result, check.Equals, "No volumes available.\n")
+}
+
+func (s *S) TestVolumePlansListInfo(c *check.C) {
+	c.Assert((&VolumePlansList{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumePlansList(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `{
+	"kubernetes": [{"Name":"nfs","Opts":{"access-modes":"ReadWriteMany","plugin":"nfs"}}, {"Name":"ebs","Opts":{"storage-class":"myebs"}}],
+	"swarm": [{"Name":"nfs","Opts":{"driver":"local", "opt": [{"type": "nfs"}]}}]
+}`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	err := (&VolumePlansList{}).Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	result := stdout.String()
+	c.Assert(result, check.Equals, `+------+-------------+-----------------------------+
+| Plan | Provisioner | Opts                        |
++------+-------------+-----------------------------+
+| ebs  | kubernetes  | storage-class: myebs        |
++------+-------------+-----------------------------+
+| nfs  | kubernetes  | access-modes: ReadWriteMany |
+|      |             | plugin: nfs                 |
++------+-------------+-----------------------------+
+| nfs  | swarm       | driver: local               |
+|      |             | opt: [map[type:nfs]]        |
++------+-------------+-----------------------------+
+`)
+}
+
+func (s *S) TestVolumePlansListJSON(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `{"kubernetes": [{"Name":"nfs","Opts":{"plugin":"nfs"}}]}`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumePlansList{}
+	command.Flags().Parse(true, []string{"--json"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `{
+  "kubernetes": [
+    {
+      "Name": "nfs",
+      "Opts": {
+        "plugin": "nfs"
+      }
+    }
+  ]
+}
+`)
+}
+
+func (s *S) TestVolumePlansListYAML(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `{"kubernetes": [{"Name":"nfs","Opts":{"plugin":"nfs"}}]}`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumePlansList{}
+	command.Flags().Parse(true, []string{"--yaml"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `kubernetes:
+- Name: nfs
+  Opts:
+    plugin: nfs
+`)
+}
+
+func (s *S) TestVolumePlansListConfigOutput(c *check.C) {
+	original := volumeClientConfig
+	volumeClientConfig = func() config.VolumeConfig { return config.VolumeConfig{Output: "yaml"} }
+	defer func() { volumeClientConfig = original }()
+	response := `{"kubernetes": [{"Name":"nfs","Opts":{"plugin":"nfs"}}]}`
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	var stdout bytes.Buffer
+	command := &VolumePlansList{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `kubernetes:
+- Name: nfs
+  Opts:
+    plugin: nfs
+`)
+	stdout.Reset()
+	command = &VolumePlansList{}
+	command.Flags().Parse(true, []string{"--json"})
+	err = command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.HasPrefix(stdout.String(), "{\n"), check.Equals, true)
+}
+
+func (s *S) TestVolumePlansListCapacityLimits(c *check.C) {
+	var stdout bytes.Buffer
+	response := `{
+	"kubernetes": [{"Name":"ebs","Opts":{"storage-class":"myebs","min-capacity":"1Gi","max-capacity":10737418240}}, {"Name":"nfs","Opts":{"plugin":"nfs","opt":[{"type":"nfs"}]}}]
+}`
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumePlansList{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `+------+-------------+--------------+--------------+----------------------+
+| Plan | Provisioner | Min Capacity | Max Capacity | Opts                 |
++------+-------------+--------------+--------------+----------------------+
+| ebs  | kubernetes  | 1Gi          | 10.0 GiB     | storage-class: myebs |
++------+-------------+--------------+--------------+----------------------+
+| nfs  | kubernetes  |              |              | opt: [map[type:nfs]] |
+|      |             |              |              | plugin: nfs          |
++------+-------------+--------------+--------------+----------------------+
+`)
+}
+
+func (s *S) TestVolumePlansListWide(c *check.C) {
+	var stdout bytes.Buffer
+	response := `{
+	"kubernetes": [{"Name":"ebs","Opts":{"storage-class":"myebs","min-capacity":"1Gi","max-capacity":10737418240}}, {"Name":"nfs","Opts":{"plugin":"nfs","opt":[{"type":"nfs"}]}}]
+}`
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumePlansList{}
+	command.Flags().Parse(true, []string{"--wide"})
+	err := command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `+------+-------------+--------------+--------------+---------------------------+
+| Plan | Provisioner | Min Capacity | Max Capacity | Opts                      |
++------+-------------+--------------+--------------+---------------------------+
+| ebs  | kubernetes  | 1Gi          | 10.0 GiB     | max-capacity: 10737418240 |
+|      |             |              |              | min-capacity: 1Gi         |
+|      |             |              |              | storage-class: myebs      |
++------+-------------+--------------+--------------+---------------------------+
+| nfs  | kubernetes  |              |              | opt: [{"type":"nfs"}]     |
+|      |             |              |              | plugin: nfs               |
++------+-------------+--------------+--------------+---------------------------+
+`)
+}
+
+func (s *S) TestVolumePlansListByProvisioner(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `{
+	"kubernetes": [{"Name":"ebs","Opts":{"storage-class":"myebs"}}],
+	"swarm": [{"Name":"nfs","Opts":{"driver":"local"}}]
+}`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumePlansList{}
+	command.Flags().Parse(true, []string{"--provisioner", "kubernetes"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `+------+-------------+----------------------+
+| Plan | Provisioner | Opts                 |
++------+-------------+----------------------+
+| ebs  | kubernetes  | storage-class: myebs |
++------+-------------+----------------------+
+`)
+}
+
+func (s *S) TestVolumePlansListByProvisionerWithoutPlans(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: `{"swarm": [{"Name":"nfs"}]}`, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumePlansList{}
+	command.Flags().Parse(true, []string{"--provisioner", "kubernetes"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "No plans for provisioner kubernetes\n")
+}
+
+func (s *S) TestVolumePlansListEmpty(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Status: http.StatusNoContent},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	err := (&VolumePlansList{}).Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	result := stdout.String()
+	c.Assert(result, check.Equals, `+------+-------------+------+
+| Plan | Provisioner | Opts |
++------+-------------+------+
+`)
+}
+
+func (s *S) TestVolumeCreateInfo(c *check.C) {
+	c.Assert((&VolumeCreate{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeCreate(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			dec := form.NewDecoder(nil)
+			dec.IgnoreCase(true)
+			dec.IgnoreUnknownKeys(true)
+			dec.UseJSONTags(false)
+			var vol volumeTypes.Volume
+			err := dec.DecodeValues(&vol, r.Form)
+			c.Assert(err, check.IsNil)
+			c.Assert(vol, check.DeepEquals, volumeTypes.Volume{
+				Name:      "vol1",
+				Plan:      volumeTypes.VolumePlan{Name: "plan1"},
+				TeamOwner: "team1",
+				Pool:      "pool1",
+				Opts:      map[string]string{"a": "1", "b": "2"},
+			})
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"-t", "team1", "-p", "pool1", "-o", "a=1", "-o", "b=2"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	result := stdout.String()
+	c.Assert(result, check.Equals, "Volume successfully created.\n")
+}
+
+func (s *S) TestVolumeCreateSpecialCharacterOpts(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			dec := form.NewDecoder(nil)
+			dec.IgnoreCase(true)
+			dec.IgnoreUnknownKeys(true)
+			dec.UseJSONTags(false)
+			var vol volumeTypes.Volume
+			err := dec.DecodeValues(&vol, r.Form)
+			c.Assert(err, check.IsNil)
+			c.Assert(vol.Opts, check.DeepEquals, map[string]string{
+				"query":         "a=1&b=2",
+				"script":        "line 1\nline 2\n",
+				"mount.options": "rw,noatime",
+			})
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"-o", "query=a=1&b=2", "-o", "script=line 1\nline 2\n", "-o", "mount.options=rw,noatime"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully created.\n")
+}
+
+func (s *S) TestVolumeCreateJSONBody(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+		CondFunc: func(r *http.Request) bool {
+			c.Assert(r.Header.Get("Content-Type"), check.Equals, "application/json")
+			var vol volumeTypes.Volume
+			err := json.NewDecoder(r.Body).Decode(&vol)
+			c.Assert(err, check.IsNil)
+			c.Assert(vol, check.DeepEquals, volumeTypes.Volume{
+				Name:      "vol1",
+				Plan:      volumeTypes.VolumePlan{Name: "plan1"},
+				TeamOwner: "team1",
+				Pool:      "pool1",
+				Opts:      map[string]string{"a": "1", "b": "x=y&z"},
+			})
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"-t", "team1", "-p", "pool1", "-o", "a=1", "-o", "b=x=y&z", "--json-body"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully created.\n")
+}
+
+func (s *S) TestVolumeCreateAndBind(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: `{"Message":"mounting volume\n"}`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("App"), check.Equals, "myapp")
+					c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--bind-app", "myapp", "--bind-mountpoint", "/mnt"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Volume successfully created.
+Binding volume "vol1" to app "myapp" at "/mnt"...
+mounting volume
+Volume successfully bound.
+`)
+}
+
+func (s *S) TestVolumeCreateAndBindConfigNoRestart(c *check.C) {
+	original := volumeClientConfig
+	volumeClientConfig = func() config.VolumeConfig { return config.VolumeConfig{NoRestart: true} }
+	defer func() { volumeClientConfig = original }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("NoRestart"), check.Equals, "true")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--bind-app", "myapp", "--bind-mountpoint", "/mnt"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stderr.String(), check.Equals, "Note: the application won't be restarted, as set by volume.noRestart in the client config. Use --no-restart=false to restart it.\n")
+}
+
+func (s *S) TestVolumeCreateAndBindFailure(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "app not found", Status: http.StatusNotFound},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "DELETE"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--bind-app", "myapp", "--bind-mountpoint", "/mnt", "-q"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, "app not found")
+	c.Assert(stderr.String(), check.Equals, `Failed to bind volume "vol1", removing it...`+"\n")
+}
+
+func (s *S) TestVolumeCreateAndBindFailureKeep(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "app not found", Status: http.StatusNotFound},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--bind-app", "myapp", "--bind-mountpoint", "/mnt", "--keep-on-bind-failure", "-q"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, `volume "vol1" was created, but binding it to app "myapp" failed: app not found`)
+	c.Assert(stderr.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeCreateAndBindFlags(c *check.C) {
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--bind-app", "myapp"})
+	err := command.Run(&cmd.Context{Args: []string{"vol1", "plan1"}}, cmd.NewClient(&http.Client{}, nil, manager))
+	c.Assert(err, check.ErrorMatches, "the --bind-app and --bind-mountpoint flags must be given together")
+	command = &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--keep-on-bind-failure"})
+	err = command.Run(&cmd.Context{Args: []string{"vol1", "plan1"}}, cmd.NewClient(&http.Client{}, nil, manager))
+	c.Assert(err, check.ErrorMatches, "the --keep-on-bind-failure flag requires --bind-app")
+	command = &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--no-restart"})
+	err = command.Run(&cmd.Context{Args: []string{"vol1", "plan1"}}, cmd.NewClient(&http.Client{}, nil, manager))
+	c.Assert(err, check.ErrorMatches, "the --no-restart flag requires --bind-app")
+}
+
+func (s *S) TestVolumeCreateDuplicatedOpts(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.Form.Get("Opts.a"), check.Equals, "2")
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"-o", "a=1", "--opt", "a=2"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully created.\n")
+	c.Assert(stderr.String(), check.Equals, `Warning: option "a" given more than once, with values "1" and "2". Using "2".`+"\n")
+}
+
+func (s *S) TestVolumeCreateDuplicatedOptsStrict(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"-o", "a=1", "-o", "a=2", "--strict-opts"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, `option "a" given more than once, with values "1" and "2"`)
+	c.Assert(stdout.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeCreateOptsFile(c *check.C) {
+	fsystem = &fstest.RecordingFs{FileContent: `# nfs options
+path = /exports/vol1
+
+capacity=10Gi
+a=from-file
+`}
+	defer func() { fsystem = nil }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.Form.Get("Opts.path"), check.Equals, "/exports/vol1")
+			c.Assert(r.Form.Get("Opts.capacity"), check.Equals, "10Gi")
+			c.Assert(r.Form.Get("Opts.a"), check.Equals, "from-flag")
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--opts-file", "opts.env", "-o", "a=from-flag"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully created.\n")
+	c.Assert(stderr.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeCreateOptsFileYAML(c *check.C) {
+	fsystem = &fstest.RecordingFs{FileContent: "path: /exports/vol1\nreplicas: 3\n"}
+	defer func() { fsystem = nil }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.Form.Get("Opts.path"), check.Equals, "/exports/vol1")
+			c.Assert(r.Form.Get("Opts.replicas"), check.Equals, "3")
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--opts-file", "opts.yaml"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully created.\n")
+}
+
+func (s *S) TestVolumeCreateOptsFileInvalidLine(c *check.C) {
+	fsystem = &fstest.RecordingFs{FileContent: "path=/exports/vol1\ncapacity\n"}
+	defer func() { fsystem = nil }()
+	var stdout bytes.Buffer
+	ctx := cmd.Context{Args: []string{"vol1", "plan1"}, Stdout: &stdout}
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--opts-file", "opts.env"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, `invalid line 2 in options file "opts.env": expected key=value`)
+}
+
+func (s *S) TestVolumeCreateInvalidName(c *check.C) {
+	for _, name := range []string{"Vol1", "my vol", "1vol", "vol_1", strings.Repeat("v", 41)} {
+		var stdout, stderr bytes.Buffer
+		ctx := cmd.Context{
+			Args:   []string{name, "plan1"},
+			Stdout: &stdout,
+			Stderr: &stderr,
+		}
+		command := &VolumeCreate{}
+		command.Flags().Parse(true, []string{})
+		err := command.Run(&ctx, nil)
+		c.Assert(err, check.ErrorMatches, `invalid volume name ".*": it must have at most 40 characters, containing only lower case letters, numbers or dashes, starting with a letter \(use --skip-name-validation to send it anyway\)`)
+		c.Assert(stdout.String(), check.Equals, "")
+	}
+}
+
+func (s *S) TestVolumeCreateSkipNameValidation(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"Vol_1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.Form.Get("Name"), check.Equals, "Vol_1")
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--skip-name-validation"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully created.\n")
+}
+
+func (s *S) TestVolumeCreateKubernetesOpts(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.Form.Get("Opts.storage-class"), check.Equals, "fast")
+			c.Assert(r.Form.Get("Opts.namespace"), check.Equals, "ns1")
+			c.Assert(r.Form.Get("Opts.capacity"), check.Equals, "1Gi")
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"-o", "capacity=1Gi", "--storage-class", "fast", "--namespace", "ns1"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stderr.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeCreateKubernetesOptsKeysFromConfig(c *check.C) {
+	original := volumeClientConfig
+	volumeClientConfig = func() config.VolumeConfig {
+		return config.VolumeConfig{StorageClassOpt: "class", NamespaceOpt: "ns"}
+	}
+	defer func() { volumeClientConfig = original }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.Form.Get("Opts.class"), check.Equals, "fast")
+			c.Assert(r.Form.Get("Opts.ns"), check.Equals, "ns1")
+			c.Assert(r.Form["Opts.storage-class"], check.IsNil)
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"-o", "class=slow", "--storage-class", "fast", "--namespace", "ns1"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stderr.String(), check.Equals, `Warning: option "class" given more than once, with values "slow" and "fast". Using "fast".`+"\n")
+}
+
+func (s *S) TestVolumeCreateTags(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.Form.Get("Opts.tsuru-tag-team"), check.Equals, "payments")
+			c.Assert(r.Form.Get("Opts.tsuru-tag-env"), check.Equals, "prod")
+			c.Assert(r.Form.Get("Opts.capacity"), check.Equals, "1Gi")
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"-o", "capacity=1Gi", "--tag", "team=payments", "--tag", "env=prod"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestVolumeCreatePoolAndTeamFromEnv(c *check.C) {
+	os.Setenv("TSURU_POOL", "envpool")
+	os.Setenv("TSURU_TEAM", "envteam")
+	defer os.Unsetenv("TSURU_POOL")
+	defer os.Unsetenv("TSURU_TEAM")
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			dec := form.NewDecoder(nil)
+			dec.IgnoreCase(true)
+			dec.IgnoreUnknownKeys(true)
+			dec.UseJSONTags(false)
+			var vol volumeTypes.Volume
+			err := dec.DecodeValues(&vol, r.Form)
+			c.Assert(err, check.IsNil)
+			c.Assert(vol.Pool, check.Equals, "envpool")
+			c.Assert(vol.TeamOwner, check.Equals, "team1")
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"-t", "team1"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully created.\n")
+}
+
+func (s *S) TestVolumeCreateWait(c *check.C) {
+	volumeWaitInterval = 0
+	defer func() { volumeWaitInterval = 2 * time.Second }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	getCond := func(r *http.Request) bool {
+		return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{Transport: cmdtest.Transport{Message: "not found", Status: http.StatusNotFound}, CondFunc: getCond},
+			{Transport: cmdtest.Transport{Message: `{"Name":"vol1","Status":"provisioning"}`, Status: http.StatusOK}, CondFunc: getCond},
+			{Transport: cmdtest.Transport{Message: `{"Name":"vol1","Status":"Ready"}`, Status: http.StatusOK}, CondFunc: getCond},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--wait"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully created.\n")
+	c.Assert(stderr.String(), check.Equals, "Waiting for volume \"vol1\" to be provisioned..\n")
+}
+
+func (s *S) TestVolumeCreateWaitWithoutStatus(c *check.C) {
+	volumeWaitInterval = 0
+	defer func() { volumeWaitInterval = 2 * time.Second }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	getCond := func(r *http.Request) bool {
+		return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{Transport: cmdtest.Transport{Message: `{"Name":"vol1"}`, Status: http.StatusOK}, CondFunc: getCond},
+			{Transport: cmdtest.Transport{Message: `{"Name":"vol1"}`, Status: http.StatusOK}, CondFunc: getCond},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--wait"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stderr.String(), check.Equals, "Waiting for volume \"vol1\" to be provisioned.\n")
+}
+
+func (s *S) TestVolumeCreateWaitFailedStatus(c *check.C) {
+	volumeWaitInterval = 0
+	defer func() { volumeWaitInterval = 2 * time.Second }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: `{"Name":"vol1","Status":"failed"}`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--wait"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, `volume "vol1" failed to be provisioned: status is "failed"`)
+}
+
+func (s *S) TestVolumeCreateQuiet(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--quiet"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeCreateShow(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: `{"Name":"vol1","Pool":"pool1","Plan":{"Name":"plan1"},"TeamOwner":"team1"}`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--show"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Volume successfully created.
+
+Name: vol1
+Plan: plan1
+Pool: pool1
+Team: team1
+
+Binds:
++-----+------------+------+
+| App | MountPoint | Mode |
++-----+------------+------+
+
+Plan Opts:
++-----+-------+
+| Key | Value |
++-----+-------+
+
+Opts:
++-----+-------+
+| Key | Value |
++-----+-------+
+`)
+}
+
+func (s *S) TestVolumeCreateJSON(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: `{"Name":"vol1","Pool":"pool1","Plan":{"Name":"plan1"},"TeamOwner":"team1"}`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--json"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	var vol volumeTypes.Volume
+	err = json.Unmarshal(stdout.Bytes(), &vol)
+	c.Assert(err, check.IsNil)
+	c.Assert(vol.Name, check.Equals, "vol1")
+	c.Assert(vol.Pool, check.Equals, "pool1")
+	c.Assert(vol.TeamOwner, check.Equals, "team1")
+}
+
+func (s *S) TestVolumeCreateValidatePlan(c *check.C) {
+	fsystem = &fstest.RecordingFs{}
+	defer func() { fsystem = nil }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "nsf"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: `{"kubernetes": [{"Name":"nfs"}, {"Name":"ebs"}]}`, Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumeplans") && r.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--validate-plan"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, `plan "nsf" not found, available plans: ebs, nfs`)
+	c.Assert(stdout.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeCreateValidatePlanExisting(c *check.C) {
+	fsystem = &fstest.RecordingFs{}
+	defer func() { fsystem = nil }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "nfs"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: `{"kubernetes": [{"Name":"nfs"}, {"Name":"ebs"}]}`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumeplans") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--validate-plan"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully created.\n")
+}
+
+func (s *S) TestVolumeCreateValidatePlanCached(c *check.C) {
+	fsystem = &fstest.RecordingFs{}
+	defer func() { fsystem = nil }()
+	var planCalls int
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: `{"kubernetes": [{"Name":"nfs"}, {"Name":"ebs"}]}`, Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			planCalls++
+			return strings.HasSuffix(r.URL.Path, "/volumeplans") && r.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	for _, args := range [][]string{{"--validate-plan"}, {"--validate-plan"}, {"--validate-plan", "--refresh-cache"}, {"--validate-plan", "--no-cache"}} {
+		var stdout, stderr bytes.Buffer
+		ctx := cmd.Context{Args: []string{"vol1", "nsf"}, Stdout: &stdout, Stderr: &stderr}
+		command := &VolumeCreate{}
+		command.Flags().Parse(true, args)
+		err := command.Run(&ctx, client)
+		c.Assert(err, check.ErrorMatches, `plan "nsf" not found, available plans: ebs, nfs`)
+	}
+	c.Assert(planCalls, check.Equals, 3)
+}
+
+func (s *S) TestVolumeUpdateInfo(c *check.C) {
+	c.Assert((&VolumeUpdate{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeUpdateSpecialCharacterOpts(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: `{"Name":"vol1","Plan":{"Name":"plan1"}}`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					dec := form.NewDecoder(nil)
+					dec.IgnoreCase(true)
+					dec.IgnoreUnknownKeys(true)
+					dec.UseJSONTags(false)
+					var vol volumeTypes.Volume
+					err := dec.DecodeValues(&vol, r.Form)
+					c.Assert(err, check.IsNil)
+					c.Assert(vol.Opts, check.DeepEquals, map[string]string{
+						"query":  "x=1&y=2",
+						"script": "#!/bin/sh\r\necho ok",
+					})
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "POST"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUpdate{}
+	command.Flags().Parse(true, []string{"-o", "query=x=1&y=2", "-o", "script=#!/bin/sh\r\necho ok"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully updated.\n")
+}
+
+func (s *S) TestEncodeVolumeBodyEscapesValues(c *check.C) {
+	body, contentType, err := encodeVolumeBody(volumeTypes.Volume{
+		Name: "vol1",
+		Opts: map[string]string{"a": "x&y=z\n"},
+	}, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(contentType, check.Equals, "application/x-www-form-urlencoded")
+	data, err := io.ReadAll(body)
+	c.Assert(err, check.IsNil)
+	values, err := url.ParseQuery(string(data))
+	c.Assert(err, check.IsNil)
+	c.Assert(values.Get("Opts.a"), check.Equals, "x&y=z\n")
+}
+
+func (s *S) TestVolumeUpdate(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: `{"Name":"vol1","Plan":{"Name":"plan1"}}`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					dec := form.NewDecoder(nil)
+					dec.IgnoreCase(true)
+					dec.IgnoreUnknownKeys(true)
+					dec.UseJSONTags(false)
+					var vol volumeTypes.Volume
+					err := dec.DecodeValues(&vol, r.Form)
+					c.Assert(err, check.IsNil)
+					c.Assert(vol, check.DeepEquals, volumeTypes.Volume{
+						Name:      "vol1",
+						Plan:      volumeTypes.VolumePlan{Name: "plan1"},
+						TeamOwner: "team1",
+						Pool:      "pool1",
+						Opts:      map[string]string{"a": "1", "b": "2"},
+					})
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "POST"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUpdate{}
+	command.Flags().Parse(true, []string{"-t", "team1", "-p", "pool1", "-o", "a=1", "-o", "b=2"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	result := stdout.String()
+	c.Assert(result, check.Equals, "Volume successfully updated.\n")
+}
+
+func (s *S) TestVolumeUpdatePlanChange(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan2"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Stdin:  strings.NewReader("y\n"),
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: `{"Name":"vol1","Plan":{"Name":"plan1"}}`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.Form.Get("Plan.Name"), check.Equals, "plan2")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "POST"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUpdate{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stderr.String(), check.Equals, "Warning: changing the plan of volume \"vol1\" from \"plan1\" to \"plan2\" may be disruptive or unsupported by the backend.\n")
+	c.Assert(stdout.String(), check.Equals, "Are you sure you want to change the plan? (y/n) Volume successfully updated.\n")
+}
+
+func (s *S) TestVolumeUpdatePlanChangeAssumeYes(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan2"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUpdate{}
+	command.Flags().Parse(true, []string{"-y"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stderr.String(), check.Equals, "")
+	c.Assert(stdout.String(), check.Equals, "Volume successfully updated.\n")
+}
+
+func (s *S) TestVolumeUpdatePlanChangeAborted(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan2"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Stdin:  strings.NewReader("n\n"),
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: `{"Name":"vol1","Plan":{"Name":"plan1"}}`, Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUpdate{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Are you sure you want to change the plan? (y/n) Abort.\n")
+}
+
+func (s *S) TestVolumeUpdateDuplicatedOptsStrict(c *check.C) {
+	command := &VolumeUpdate{}
+	command.Flags().Parse(true, []string{"-o", "size=1Gi", "-o", "size=2Gi", "--strict-opts"})
+	err := command.Run(&cmd.Context{Args: []string{"vol1", "plan1"}}, nil)
+	c.Assert(err, check.ErrorMatches, `option "size" given more than once, with values "1Gi" and "2Gi"`)
+}
+
+func (s *S) TestVolumeUpdateExitCodes(c *check.C) {
+	tests := []struct {
+		status int
+		code   int
+	}{
+		{http.StatusNotFound, VolumeExitNotFound},
+		{http.StatusForbidden, VolumeExitForbidden},
+		{http.StatusConflict, VolumeExitConflict},
+		{http.StatusInternalServerError, 0},
+	}
+	for _, tt := range tests {
+		trans := &cmdtest.ConditionalTransport{
+			Transport: cmdtest.Transport{Message: "some error", Status: tt.status},
+			CondFunc: func(r *http.Request) bool {
+				return strings.HasSuffix(r.URL.Path, "/volumes/vol1")
+			},
+		}
+		client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+		command := &VolumeUpdate{}
+		command.Flags().Parse(true, []string{})
+		err := command.Run(&cmd.Context{Args: []string{"vol1", "plan1"}}, client)
+		c.Assert(err, check.ErrorMatches, "some error")
+		var code int
+		var exitErr interface{ ExitCode() int }
+		if errors.As(err, &exitErr) {
+			code = exitErr.ExitCode()
+		}
+		c.Assert(code, check.Equals, tt.code, check.Commentf("status %d", tt.status))
+	}
+}
+
+func (s *S) TestVolumeInfoCapacityBytes(c *check.C) {
+	fsystem = &fstest.RecordingFs{}
+	defer func() { fsystem = nil }()
+	for _, tt := range []struct {
+		args     []string
+		capacity string
+	}{
+		{nil, "10.0 GiB"},
+		{[]string{"--raw"}, "10737418240"},
+	} {
+		var stdout bytes.Buffer
+		trans := &cmdtest.MultiConditionalTransport{
+			ConditionalTransports: []cmdtest.ConditionalTransport{
+				{
+					Transport: cmdtest.Transport{Message: `{"Name":"vol1","Plan":{"Name":"nfs"},"Opts":{"capacity":"10737418240"}}`, Status: http.StatusOK},
+					CondFunc: func(req *http.Request) bool {
+						return strings.HasSuffix(req.URL.Path, "/volumes/vol1") && req.Method == "GET"
+					},
+				},
+				{
+					Transport: cmdtest.Transport{Message: `{"kubernetes": [{"Name":"nfs"}]}`, Status: http.StatusOK},
+					CondFunc: func(req *http.Request) bool {
+						return strings.HasSuffix(req.URL.Path, "/volumeplans") && req.Method == "GET"
+					},
+				},
+			},
+		}
+		client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+		command := &VolumeInfo{}
+		command.Flags().Parse(true, tt.args)
+		err := command.Run(&cmd.Context{Args: []string{"vol1"}, Stdout: &stdout}, client)
+		c.Assert(err, check.IsNil)
+		c.Assert(stdout.String(), check.Matches, "(?s).*\nCapacity: "+tt.capacity+"\n.*")
+	}
+}
+
+func (s *S) TestVolumeInfoJSONError(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "user doesn't have access to <vol1>", Status: http.StatusForbidden},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeInfo{}
+	command.Flags().Parse(true, []string{"--json"})
+	err := command.Run(&cmd.Context{Args: []string{"vol1"}, Stdout: &stdout, Stderr: &stderr}, client)
+	c.Assert(errors.Is(err, cmd.ErrAbortCommand), check.Equals, true)
+	var exitErr interface{ ExitCode() int }
+	c.Assert(errors.As(err, &exitErr), check.Equals, true)
+	c.Assert(exitErr.ExitCode(), check.Equals, VolumeExitForbidden)
+	c.Assert(stdout.String(), check.Equals, "")
+	c.Assert(stderr.String(), check.Equals, `{"error":"user doesn't have access to <vol1>"}`+"\n")
+}
+
+func (s *S) TestVolumeDeleteInfo(c *check.C) {
+	c.Assert((&VolumeDelete{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeDeleteQuiet(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "DELETE"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeDelete{}
+	command.Flags().Parse(true, []string{"-y", "-q"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeDelete(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Stdin:  strings.NewReader("y\n"),
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: `{"Name":"vol1","Binds":null}`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "DELETE"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeDelete{}
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	result := stdout.String()
+	c.Assert(result, check.Equals, `Are you sure you want to delete volume "vol1"? (y/n) Volume successfully deleted.`+"\n")
+}
+
+func (s *S) TestVolumeDeleteBoundVolumeAbort(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Stdin:  strings.NewReader("n\n"),
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: `{"Name":"vol1","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt","Volume":"vol1"}}]}`, Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeDelete{}
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	result := stdout.String()
+	c.Assert(result, check.Equals, `Are you sure you want to delete volume "vol1" (bound 1 time(s))? (y/n) Abort.`+"\n")
+}
+
+func (s *S) TestVolumeDeleteAssumeYes(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "DELETE"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeDelete{}
+	command.Flags().Parse(true, []string{"-y"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully deleted.\n")
+}
+
+func (s *S) TestVolumeDeleteForce(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	volume := `{"Name":"vol1","Binds":[{"ID":{"App":"app1","MountPoint":"/mnt1","Volume":"vol1"}},{"ID":{"App":"app2","MountPoint":"/mnt2","Volume":"vol1"}}]}`
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: volume, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					c.Assert(r.URL.Query().Get("App"), check.Equals, "app1")
+					c.Assert(r.URL.Query().Get("MountPoint"), check.Equals, "/mnt1")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					c.Assert(r.URL.Query().Get("App"), check.Equals, "app2")
+					c.Assert(r.URL.Query().Get("MountPoint"), check.Equals, "/mnt2")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "DELETE"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeDelete{}
+	command.Flags().Parse(true, []string{"-y", "--force"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Unbinding volume "vol1" from app "app1" at "/mnt1"...
+Unbinding volume "vol1" from app "app2" at "/mnt2"...
+Volume successfully deleted.
+`)
+}
+
+func (s *S) TestVolumeDeleteBoundWithoutForce(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	volume := `{"Name":"vol1","Binds":[{"ID":{"App":"app1","MountPoint":"/mnt1","Volume":"vol1"}}]}`
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: "volume still binded", Status: http.StatusBadRequest},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "DELETE"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: volume, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeDelete{}
+	command.Flags().Parse(true, []string{"-y"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, `volume "vol1" is still bound to 1 app\(s\), use --force to unbind it before deleting: volume still binded`)
+}
+
+func (s *S) TestVolumeDeleteMultiple(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "vol2", "vol3"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "DELETE"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "volume not found", Status: http.StatusNotFound},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol2") && r.Method == "DELETE"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "volume not found", Status: http.StatusNotFound},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol2") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol3") && r.Method == "DELETE"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeDelete{}
+	command.Flags().Parse(true, []string{"-y"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, "failed to delete 1 of 3 volumes")
+	c.Assert(stdout.String(), check.Equals, `Volume "vol1" successfully deleted.
+Volume "vol3" successfully deleted.
+`)
+	c.Assert(stderr.String(), check.Equals, `Failed to delete volume "vol2": volume not found`+"\n")
+}
+
+func (s *S) TestVolumeDeleteByFilter(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	volumes := `[
+		{"Name":"ephemeral-2","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team1"},
+		{"Name":"ephemeral-1","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team1"},
+		{"Name":"permanent","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team1"}
+]`
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: volumes, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					c.Assert(r.URL.Query().Get("pool"), check.Equals, "pool1")
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/ephemeral-1") && r.Method == "DELETE"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/ephemeral-2") && r.Method == "DELETE"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeDelete{}
+	command.Flags().Parse(true, []string{"--by-filter", "--pool", "pool1", "--name-regex", "^ephemeral-", "-y"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `The following volumes will be deleted:
+ - ephemeral-1
+ - ephemeral-2
+Volume "ephemeral-1" successfully deleted.
+Volume "ephemeral-2" successfully deleted.
+`)
+}
+
+func (s *S) TestVolumeDeleteByFilterRequiresFilterAndAssumeYes(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	command := &VolumeDelete{}
+	command.Flags().Parse(true, []string{"--by-filter", "-y"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, "at least one filter is required when using --by-filter")
+	command = &VolumeDelete{}
+	command.Flags().Parse(true, []string{"--by-filter", "--team", "team1"})
+	err = command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, "--assume-yes is required when using --by-filter")
+}
+
+func (s *S) TestVolumeDeleteWithoutArgs(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	err := (&VolumeDelete{}).Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, "at least one volume name is required, or use --by-filter")
+}
+
+func (s *S) TestVolumeBindInfo(c *check.C) {
+	c.Assert((&VolumeBind{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeBind(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.FormValue("App"), check.Equals, "myapp")
+			c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt")
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	result := stdout.String()
+	c.Assert(result, check.Equals, "Volume successfully bound.\nMount point \"/mnt\" is now active in app \"myapp\" (app restarted).\n")
+}
+
+func (s *S) TestVolumeBindJSONBody(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			c.Assert(r.Header.Get("Content-Type"), check.Equals, "application/json")
+			var body map[string]interface{}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			c.Assert(err, check.IsNil)
+			c.Assert(body, check.DeepEquals, map[string]interface{}{
+				"App":        "myapp",
+				"MountPoint": "/mnt",
+				"ReadOnly":   true,
+				"NoRestart":  false,
+			})
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "-r", "--json-body"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestVolumeBindNormalizedMountPoint(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt//data/"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt/data")
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully bound.\nMount point \"/mnt/data\" is now active in app \"myapp\" (app restarted).\n")
+	c.Assert(stderr.String(), check.Equals, `Warning: using mount point "/mnt/data" instead of "/mnt//data/".`+"\n")
+}
+
+func (s *S) TestVolumeBindIfNotBound(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: `{"Name":"vol1","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt","Volume":"vol1"}}]}`, Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--if-not-bound"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Volume "vol1" is already bound to app "myapp" at "/mnt".`+"\n")
+}
+
+func (s *S) TestVolumeBindIfNotBoundOtherApps(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	volume := `{"Name":"vol1","Binds":[{"ID":{"App":"app1","MountPoint":"/mnt","Volume":"vol1"}},{"ID":{"App":"app2","MountPoint":"/data","Volume":"vol1"}}]}`
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: volume, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("App"), check.Equals, "app2")
+					c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "app1,app2", "--if-not-bound"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Volume "vol1" is already bound to app "app1" at "/mnt".
+Volume successfully bound.
+Mount point "/mnt" is now active in app "app2" (app restarted).
+`)
+}
+
+func (s *S) TestVolumeBindWait(c *check.C) {
+	volumeWaitInterval = 0
+	defer func() { volumeWaitInterval = 2 * time.Second }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	getCond := func(r *http.Request) bool {
+		return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+				},
+			},
+			{Transport: cmdtest.Transport{Message: `{"Name":"vol1","Binds":[{"ID":{"App":"myapp","MountPoint":"/data"}}]}`, Status: http.StatusOK}, CondFunc: getCond},
+			{Transport: cmdtest.Transport{Message: `{"Name":"vol1","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt"}}]}`, Status: http.StatusOK}, CondFunc: getCond},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--wait"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully bound.\nMount point \"/mnt\" is now active in app \"myapp\" (app restarted).\n")
+	c.Assert(stderr.String(), check.Equals, "Waiting for volume \"vol1\" to be mounted at \"/mnt\".\n")
+}
+
+func (s *S) TestVolumeBindWaitTimeout(c *check.C) {
+	volumeWaitInterval = time.Hour
+	defer func() { volumeWaitInterval = 2 * time.Second }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: `{"Name":"vol1","Binds":[]}`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--wait", "--wait-timeout", "1m"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, `timed out waiting for volume "vol1" to be mounted at "/mnt" after 1m0s, bind not present for app\(s\): myapp`)
+	c.Assert(stdout.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeBindPlain(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: `{"Message":"---- restarting the app ----\n"}`, Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--plain"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Binding volume "vol1" to app "myapp" at "/mnt"...
+Volume successfully bound.
+Mount point "/mnt" is now active in app "myapp" (app restarted).
+`)
+}
+
+func (s *S) TestVolumeBindPlainStreamError(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: `{"Message":"restarting\n"}` + "\n" + `{"Error":"mount failed"}` + "\n", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--plain"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, "mount failed")
+	c.Assert(stdout.String(), check.Equals, "Binding volume \"vol1\" to app \"myapp\" at \"/mnt\"...\n")
+}
+
+func (s *S) TestVolumeBindNoRestart(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.FormValue("App"), check.Equals, "myapp")
+			c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt")
+			c.Assert(r.FormValue("NoRestart"), check.Equals, "true")
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--no-restart"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	result := stdout.String()
+	c.Assert(result, check.Equals, "Volume successfully bound.\nMount point \"/mnt\" is now active in app \"myapp\" (restart skipped with --no-restart).\n")
+}
+
+func (s *S) TestVolumeBindRestartStrategy(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.FormValue("App"), check.Equals, "myapp")
+			c.Assert(r.FormValue("NoRestart"), check.Not(check.Equals), "true")
+			c.Assert(r.FormValue("RestartStrategy"), check.Equals, "rolling")
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--restart-strategy", "rolling"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully bound.\nMount point \"/mnt\" is now active in app \"myapp\" (app restarted with the rolling strategy).\n")
+}
+
+func (s *S) TestVolumeBindRestartStrategyNoRestart(c *check.C) {
+	var stdout bytes.Buffer
+	ctx := cmd.Context{Args: []string{"vol1", "/mnt"}, Stdout: &stdout}
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--no-restart", "--restart-strategy", "rolling"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, "the --no-restart and --restart-strategy flags are conflicting")
+}
+
+func (s *S) TestVolumeBindRO(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.FormValue("App"), check.Equals, "myapp")
+			c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt")
+			c.Assert(r.FormValue("ReadOnly"), check.Equals, "true")
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--readonly"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	result := stdout.String()
+	c.Assert(result, check.Equals, "Volume successfully bound.\nMount point \"/mnt\" is now active in app \"myapp\" (app restarted).\n")
+}
+
+func (s *S) TestVolumeBindModeRO(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.FormValue("ReadOnly"), check.Equals, "true")
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--mode", "ro"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully bound.\nMount point \"/mnt\" is now active in app \"myapp\" (app restarted).\n")
+}
+
+func (s *S) TestVolumeBindInvalidMode(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--mode", "rw", "-r"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, `the --mode rw and -r/--readonly flags are conflicting`)
+	command = &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--mode", "wo"})
+	err = command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, `invalid mode "wo", valid options are: ro, rw`)
+}
+
+func (s *S) TestVolumeBindSubPath(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.FormValue("App"), check.Equals, "myapp")
+			c.Assert(r.FormValue("SubPath"), check.Equals, "data/logs")
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--subpath", "data/logs"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully bound.\nMount point \"/mnt\" is now active in app \"myapp\" (app restarted).\n")
+}
+
+func (s *S) TestVolumeBindConfigNoRestart(c *check.C) {
+	original := volumeClientConfig
+	volumeClientConfig = func() config.VolumeConfig { return config.VolumeConfig{NoRestart: true} }
+	defer func() { volumeClientConfig = original }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.FormValue("NoRestart"), check.Equals, "true")
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully bound.\nMount point \"/mnt\" is now active in app \"myapp\" (restart skipped with --no-restart).\n")
+	c.Assert(stderr.String(), check.Equals, "Note: the application won't be restarted, as set by volume.noRestart in the client config. Use --no-restart=false to restart it.\n")
+}
+
+func (s *S) TestVolumeBindConfigNoRestartOverridden(c *check.C) {
+	original := volumeClientConfig
+	volumeClientConfig = func() config.VolumeConfig { return config.VolumeConfig{NoRestart: true} }
+	defer func() { volumeClientConfig = original }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.FormValue("NoRestart"), check.Not(check.Equals), "true")
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--no-restart=false"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully bound.\nMount point \"/mnt\" is now active in app \"myapp\" (app restarted).\n")
+	c.Assert(stderr.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeBindPropagation(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.FormValue("Propagation"), check.Equals, "HostToContainer")
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--propagation", "HostToContainer"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestVolumeBindInvalidPropagation(c *check.C) {
+	ctx := cmd.Context{Args: []string{"vol1", "/mnt"}, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--propagation", "hosttocontainer"})
+	err := command.Run(&ctx, cmd.NewClient(&http.Client{}, nil, manager))
+	c.Assert(err, check.ErrorMatches, `invalid propagation mode "hosttocontainer", valid options are: None, HostToContainer, Bidirectional`)
+}
+
+func (s *S) TestVolumeBindWithoutSubPath(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			_, ok := r.Form["SubPath"]
+			c.Assert(ok, check.Equals, false)
+			_, ok = r.Form["Propagation"]
+			c.Assert(ok, check.Equals, false)
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestVolumeBindMultipleApps(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("App"), check.Equals, "app1")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "app not found", Status: http.StatusNotFound},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("App"), check.Equals, "app2")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("App"), check.Equals, "app3")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "app1,app2,app3"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, `failed to bind volume "vol1" to app\(s\): app2`)
+	c.Assert(stdout.String(), check.Equals, `Binding volume "vol1" to app "app1"...
+Binding volume "vol1" to app "app2"...
+Binding volume "vol1" to app "app3"...
+`)
+	c.Assert(stderr.String(), check.Equals, `Failed to bind volume "vol1" to app "app2": app not found`+"\n")
+}
+
+type blockingTransport struct{}
+
+func (blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func (s *S) TestVolumeBindSummary(c *check.C) {
+	command := &VolumeBind{}
+	c.Assert(command.bindSummary("/mnt", []string{"app1", "app2"}), check.Equals, `Mount point "/mnt" is now active in apps app1, app2 (apps restarted).`)
+	command.noRestart = true
+	c.Assert(command.bindSummary("/mnt", []string{"app1"}), check.Equals, `Mount point "/mnt" is now active in app "app1" (restart skipped with --no-restart).`)
+}
+
+func (s *S) TestVolumeBindRelativeMountPoint(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "data"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, `invalid mount point "data": it must be an absolute path such as "/data" \(use --skip-path-check to send it anyway\)`)
+}
+
+func (s *S) TestVolumeBindWithoutApp(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/data"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, `(?s)The name of the app is required.*`)
+	command = &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", " , "})
+	err = command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, `(?s)invalid app name " , ".*Use the --app flag.*`)
+}
+
+func (s *S) TestVolumeBindSkipPathCheck(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "data"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.FormValue("MountPoint"), check.Equals, "data")
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--skip-path-check"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully bound.\nMount point \"data\" is now active in app \"myapp\" (app restarted).\n")
+}
+
+func (s *S) TestVolumeBindDryRun(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			c.Errorf("unexpected request to %s", r.URL.Path)
+			return false
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "app1,app2", "-r", "--subpath", "logs", "--dry-run"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Would bind volume "vol1" to app "app1":
+  POST http://localhost:8080/1.4/volumes/vol1/bind
+  App: app1
+  MountPoint: /mnt
+  ReadOnly: true
+  NoRestart: false
+  SubPath: logs
+Would bind volume "vol1" to app "app2":
+  POST http://localhost:8080/1.4/volumes/vol1/bind
+  App: app2
+  MountPoint: /mnt
+  ReadOnly: true
+  NoRestart: false
+  SubPath: logs
+`)
+}
+
+func (s *S) TestVolumeBindTimeout(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	client := cmd.NewClient(&http.Client{Transport: blockingTransport{}}, nil, manager)
+	command := &VolumeBind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--timeout", "10ms"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, "operation timed out after 10ms")
+}
+
+func (s *S) TestVolumeUnbindInfo(c *check.C) {
+	c.Assert((&VolumeUnbind{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeUnbind(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
 			r.ParseForm()
 			c.Assert(r.FormValue("App"), check.Equals, "myapp")
 			c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt")
-			c.Assert(r.FormValue("ReadOnly"), check.Equals, "true")
-			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "-y"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	result := stdout.String()
+	c.Assert(result, check.Equals, "Volume successfully unbound.\n")
+}
+
+func (s *S) TestVolumeUnbindConfigNoRestart(c *check.C) {
+	original := volumeClientConfig
+	volumeClientConfig = func() config.VolumeConfig { return config.VolumeConfig{NoRestart: true} }
+	defer func() { volumeClientConfig = original }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.FormValue("NoRestart"), check.Equals, "true")
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"-a", "myapp"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully unbound.\n")
+	c.Assert(stderr.String(), check.Equals, "Note: the application won't be restarted, as set by volume.noRestart in the client config. Use --no-restart=false to restart it.\n")
+}
+
+func (s *S) TestVolumeUnbindPlain(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: `{"Message":"---- restarting the app ----\n"}`, Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "-y", "--plain"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Unbinding volume \"vol1\" from app \"myapp\" at \"/mnt\"...\nVolume successfully unbound.\n")
+}
+
+func (s *S) TestVolumeUnbindConfirmation(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Stdin:  strings.NewReader("y\n"),
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"-a", "myapp"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Unbinding volume "vol1" from app "myapp" at "/mnt" will restart the app. Are you sure you want to continue? (y/n) Volume successfully unbound.`+"\n")
+}
+
+func (s *S) TestVolumeUnbindConfirmationRejected(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Stdin:  strings.NewReader("n\n"),
+	}
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"-a", "myapp"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Unbinding volume "vol1" from app "myapp" at "/mnt" will restart the app. Are you sure you want to continue? (y/n) Abort.`+"\n")
+}
+
+func (s *S) TestVolumeUnbindNoRestart(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			r.ParseForm()
+			c.Assert(r.FormValue("App"), check.Equals, "myapp")
+			c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt")
+			c.Assert(r.FormValue("NoRestart"), check.Equals, "true")
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--no-restart"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	result := stdout.String()
+	c.Assert(result, check.Equals, "Volume successfully unbound.\n")
+}
+
+func (s *S) TestVolumeUnbindTimeout(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	client := cmd.NewClient(&http.Client{Transport: blockingTransport{}}, nil, manager)
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "-y", "--timeout", "10ms"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, "operation timed out after 10ms")
+}
+
+func (s *S) TestVolumeUnbindNormalizedMountPoint(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt/data/../"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: `{"Name":"vol1","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt/","Volume":"vol1"}}]}`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt/")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "-y"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully unbound.\n")
+	c.Assert(stderr.String(), check.Equals, `Warning: using mount point "/mnt" instead of "/mnt/data/../".`+"\n")
+}
+
+func (s *S) TestVolumeUnbindIfBound(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: `{"Name":"vol1","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt","Volume":"vol1"}}]}`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "-y", "--if-bound"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully unbound.\n")
+}
+
+func (s *S) TestVolumeUnbindIfBoundNotBound(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/mnt"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: `{"Name":"vol1","Binds":[{"ID":{"App":"otherapp","MountPoint":"/mnt","Volume":"vol1"}}]}`, Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"-a", "myapp", "--if-bound"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Volume "vol1" is not bound to app "myapp" at "/mnt".`+"\n")
+}
+
+func (s *S) TestVolumeUnbindAll(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	volume := `{"Name":"vol1","Binds":[{"ID":{"App":"app1","MountPoint":"/mnt1","Volume":"vol1"}},{"ID":{"App":"app2","MountPoint":"/mnt2","Volume":"vol1"}}]}`
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: volume, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					c.Assert(r.URL.Query().Get("App"), check.Equals, "app1")
+					c.Assert(r.URL.Query().Get("MountPoint"), check.Equals, "/mnt1")
+					c.Assert(r.URL.Query().Get("NoRestart"), check.Equals, "true")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					c.Assert(r.URL.Query().Get("App"), check.Equals, "app2")
+					c.Assert(r.URL.Query().Get("MountPoint"), check.Equals, "/mnt2")
+					c.Assert(r.URL.Query().Get("NoRestart"), check.Equals, "true")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"--all", "--no-restart"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Unbinding volume "vol1" from app "app1" at "/mnt1"...
+Unbinding volume "vol1" from app "app2" at "/mnt2"...
+Volume successfully unbound from 2 bind(s).
+`)
+}
+
+func (s *S) TestVolumeUnbindAllNoBinds(c *check.C) {
+	for _, tt := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--all"}, "No binds to remove.\n"},
+		{[]string{"--all", "-q"}, ""},
+	} {
+		var stdout bytes.Buffer
+		trans := &cmdtest.ConditionalTransport{
+			Transport: cmdtest.Transport{Message: `{"Name":"vol1"}`, Status: http.StatusOK},
+			CondFunc: func(r *http.Request) bool {
+				return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+			},
+		}
+		client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+		command := &VolumeUnbind{}
+		command.Flags().Parse(true, tt.args)
+		err := command.Run(&cmd.Context{Args: []string{"vol1"}, Stdout: &stdout}, client)
+		c.Assert(err, check.IsNil)
+		c.Assert(stdout.String(), check.Equals, tt.expected)
+	}
+}
+
+func (s *S) TestVolumeUnbindAllApps(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/data"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	volume := `{"Name":"vol1","Binds":[{"ID":{"App":"app1","MountPoint":"/data","Volume":"vol1"}},{"ID":{"App":"app2","MountPoint":"/data","Volume":"vol1"}},{"ID":{"App":"app3","MountPoint":"/logs","Volume":"vol1"}}]}`
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: volume, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "unable to unmount", Status: http.StatusInternalServerError},
+				CondFunc: func(r *http.Request) bool {
+					c.Assert(r.URL.Query().Get("App"), check.Equals, "app1")
+					c.Assert(r.URL.Query().Get("MountPoint"), check.Equals, "/data")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					c.Assert(r.URL.Query().Get("App"), check.Equals, "app2")
+					c.Assert(r.URL.Query().Get("MountPoint"), check.Equals, "/data")
+					c.Assert(r.URL.Query().Get("NoRestart"), check.Equals, "true")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"--all-apps", "--no-restart"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, `failed to unbind volume "vol1" from app\(s\): app1`)
+	c.Assert(stdout.String(), check.Equals, `Unbinding volume "vol1" from app "app1" at "/data"...
+Unbinding volume "vol1" from app "app2" at "/data"...
+`)
+	c.Assert(stderr.String(), check.Matches, `Failed to unbind volume "vol1" from app "app1": .*unable to unmount.*\n`)
+}
+
+func (s *S) TestVolumeUnbindAllAppsRequiresMountPoint(c *check.C) {
+	var stdout bytes.Buffer
+	ctx := cmd.Context{Args: []string{"vol1"}, Stdout: &stdout}
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"--all-apps"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, "the mount point is required with --all-apps")
+	command = &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"--all-apps", "-a", "app1"})
+	err = command.Run(&cmd.Context{Args: []string{"vol1", "/data"}, Stdout: &stdout}, nil)
+	c.Assert(err, check.ErrorMatches, "the --app flag can't be used with --all-apps")
+}
+
+func (s *S) TestVolumeUnbindWithoutMountPoint(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"-a", "myapp"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, "the mount point is required unless --all is used")
+}
+
+func (s *S) TestVolumeUnbindMultipleApps(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "/data"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	command := &VolumeUnbind{}
+	command.Flags().Parse(true, []string{"-a", "app1,app2"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, "only one app can be given to --app when unbinding a single mount point")
+}
+
+func (s *S) TestVolumeClientSideFilter(c *check.C) {
+	volumes := []volumeTypes.Volume{
+
+		{
+			Name:      "gcp-volume-01",
+			Pool:      "gcp-pool-01",
+			Plan:      volumeTypes.VolumePlan{Name: "big"},
+			TeamOwner: "their-team",
+		},
+		{
+			Name:      "gcp-volume-02",
+			Pool:      "gcp-pool-02",
+			Plan:      volumeTypes.VolumePlan{Name: "small"},
+			TeamOwner: "my-team",
+		},
+		{
+			Name:      "aws-volume-01",
+			Pool:      "aws-pool-01",
+			Plan:      volumeTypes.VolumePlan{Name: "small"},
+			TeamOwner: "their-team",
+			Binds: []volumeTypes.VolumeBind{
+				{ID: volumeTypes.VolumeBindID{App: "myapp", MountPoint: "/mnt", Volume: "aws-volume-01"}},
+			},
+		},
+	}
+
+	filters := []volumeFilter{
+		{
+			name: "gcp",
+		},
+		{
+			name: "aws",
+		},
+		{
+			pool: "aws-pool-01",
+		},
+
+		{
+			name: "gcp",
+			pool: "gcp-pool-02",
+		},
+
+		{
+			plan: "small",
+		},
+
+		{
+			teamOwner: "my-team",
+		},
+
+		{
+			bound: true,
+		},
+
+		{
+			unbound: true,
+		},
+
+		{
+			nameRegex: "^(aws|gcp)-volume-01$",
+		},
+
+		{
+			app: "myapp",
+		},
+
+		{
+			app: "my",
+		},
+	}
+
+	expectedResults := [][]string{
+		{"gcp-volume-01", "gcp-volume-02"},
+		{"aws-volume-01"},
+		{"aws-volume-01"},
+		{"gcp-volume-02"},
+		{"gcp-volume-02", "aws-volume-01"},
+		{"gcp-volume-02"},
+		{"aws-volume-01"},
+		{"gcp-volume-01", "gcp-volume-02"},
+		{"gcp-volume-01", "aws-volume-01"},
+		{"aws-volume-01"},
+		{},
+	}
+
+	for i := range filters {
+		cl := VolumeList{
+			filter: filters[i],
+		}
+		c.Assert(cl.filter.validate(), check.IsNil)
+
+		filteredVolumes := cl.clientSideFilter(volumes)
+		result := []string{}
+		for _, volume := range filteredVolumes {
+			result = append(result, volume.Name)
+		}
+
+		if !c.Check(result, check.DeepEquals, expectedResults[i]) {
+			c.Errorf("Failed to test case: %d, %#v", i, filters[i])
+		}
+	}
+}
+
+func (s *S) TestVolumeListMultipleTeams(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `[
+		{"Name":"a-vol","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team1"},
+		{"Name":"b-vol","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team2"},
+		{"Name":"c-vol","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team3"}
+]`
+	ctx := cmd.Context{
+		Args:   []string{},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			c.Assert(req.URL.Query()["teamOwner"], check.DeepEquals, []string{"team1", "team3"})
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"-q", "--team", "team1, team3"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "a-vol\nc-vol\n")
+}
+
+func (s *S) TestVolumeListEmptyTeam(c *check.C) {
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--team", "team1,,team2"})
+	err := command.Run(&cmd.Context{}, cmd.NewClient(&http.Client{}, nil, manager))
+	c.Assert(err, check.ErrorMatches, `invalid team list "team1,,team2": team names can't be empty`)
+}
+
+func (s *S) TestVolumeListOptFilter(c *check.C) {
+	response := `[
+		{"Name":"a-vol","Pool":"pool1","Plan":{"Name":"ebs"},"TeamOwner":"team1","Opts":{"storage-class":"fast","zone":"a"}},
+		{"Name":"b-vol","Pool":"pool1","Plan":{"Name":"ebs"},"TeamOwner":"team1","Opts":{"storage-class":"slow"}},
+		{"Name":"c-vol","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team1"}
+]`
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--opt", "storage-class=fast"}, "a-vol\n"},
+		{[]string{"--opt", "storage-class"}, "a-vol\nb-vol\n"},
+		{[]string{"--opt", "storage-class", "--opt", "zone=b"}, ""},
+	}
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		command := &VolumeList{}
+		command.Flags().Parse(true, append([]string{"-q"}, tt.args...))
+		err := command.Run(&cmd.Context{Stdout: &stdout, Stderr: &bytes.Buffer{}}, client)
+		c.Assert(err, check.IsNil)
+		c.Check(stdout.String(), check.Equals, tt.expected, check.Commentf("args %v", tt.args))
+	}
+}
+
+func (s *S) TestVolumeOptMatchFlagInvalid(c *check.C) {
+	var f volumeOptMatchFlag
+	err := f.Set("=fast")
+	c.Assert(err, check.ErrorMatches, `invalid option filter "=fast", it must be on the form "key" or "key=value"`)
+	c.Assert(f.Set("storage-class=fast"), check.IsNil)
+	c.Assert(f.Set("zone"), check.IsNil)
+	c.Assert(f.String(), check.Equals, "storage-class=fast,zone")
+}
+
+func (s *S) TestVolumeRenameInfo(c *check.C) {
+	c.Assert((&VolumeRename{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeRename(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"old", "new"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	volume := `{"Name":"old","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team1","Opts":{"capacity":"1Gi"},"Binds":[{"ID":{"App":"app1","MountPoint":"/mnt","Volume":"old"},"ReadOnly":true},{"ID":{"App":"app1","MountPoint":"/data","Volume":"old"}}]}`
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: volume, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/old") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					dec := form.NewDecoder(nil)
+					dec.IgnoreCase(true)
+					dec.IgnoreUnknownKeys(true)
+					dec.UseJSONTags(false)
+					var vol volumeTypes.Volume
+					err := dec.DecodeValues(&vol, r.Form)
+					c.Assert(err, check.IsNil)
+					c.Assert(vol, check.DeepEquals, volumeTypes.Volume{
+						Name:      "new",
+						Plan:      volumeTypes.VolumePlan{Name: "nfs"},
+						TeamOwner: "team1",
+						Pool:      "pool1",
+						Opts:      map[string]string{"capacity": "1Gi"},
+					})
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					c.Assert(r.URL.Query().Get("MountPoint"), check.Equals, "/mnt")
+					c.Assert(r.URL.Query().Get("NoRestart"), check.Equals, "true")
+					return strings.HasSuffix(r.URL.Path, "/volumes/old/bind") && r.Method == "DELETE"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("App"), check.Equals, "app1")
+					c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt")
+					c.Assert(r.FormValue("ReadOnly"), check.Equals, "true")
+					c.Assert(r.FormValue("NoRestart"), check.Equals, "true")
+					return strings.HasSuffix(r.URL.Path, "/volumes/new/bind") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					c.Assert(r.URL.Query().Get("MountPoint"), check.Equals, "/data")
+					c.Assert(r.URL.Query().Get("NoRestart"), check.Equals, "true")
+					return strings.HasSuffix(r.URL.Path, "/volumes/old/bind") && r.Method == "DELETE"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("MountPoint"), check.Equals, "/data")
+					c.Assert(r.FormValue("NoRestart"), check.Not(check.Equals), "true")
+					return strings.HasSuffix(r.URL.Path, "/volumes/new/bind") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/old") && r.Method == "DELETE"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeRename{}
+	command.Flags().Parse(true, []string{"-y"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Creating volume "new"...
+Unbinding volume "old" from app "app1" at "/mnt"...
+Binding volume "new" to app "app1" at "/mnt"...
+Unbinding volume "old" from app "app1" at "/data"...
+Binding volume "new" to app "app1" at "/data"...
+Removing volume "old"...
+Volume "old" successfully renamed to "new".
+`)
+}
+
+func (s *S) TestVolumeRenameInvalidName(c *check.C) {
+	command := &VolumeRename{}
+	command.Flags().Parse(true, []string{"-y"})
+	err := command.Run(&cmd.Context{Args: []string{"old", ""}}, cmd.NewClient(&http.Client{}, nil, manager))
+	c.Assert(err, check.ErrorMatches, "the new volume name can't be empty")
+	err = command.Run(&cmd.Context{Args: []string{"old", "old"}}, cmd.NewClient(&http.Client{}, nil, manager))
+	c.Assert(err, check.ErrorMatches, `volume "old" is already named "old"`)
+}
+
+func (s *S) TestVolumeRenameRollback(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"old", "new"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	volume := `{"Name":"old","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team1","Binds":[{"ID":{"App":"app1","MountPoint":"/mnt","Volume":"old"}},{"ID":{"App":"app2","MountPoint":"/mnt","Volume":"old"}}]}`
+	bindRequest := func(volumeName, method, app string) cmdtest.ConditionalTransport {
+		return cmdtest.ConditionalTransport{
+			Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+			CondFunc: func(r *http.Request) bool {
+				r.ParseForm()
+				c.Assert(r.FormValue("App"), check.Equals, app)
+				return strings.HasSuffix(r.URL.Path, "/volumes/"+volumeName+"/bind") && r.Method == method
+			},
+		}
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: volume, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/old") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			bindRequest("old", "DELETE", "app1"),
+			bindRequest("new", "POST", "app1"),
+			bindRequest("old", "DELETE", "app2"),
+			{
+				Transport: cmdtest.Transport{Message: "bind failed", Status: http.StatusInternalServerError},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/new/bind") && r.Method == "POST"
+				},
+			},
+			bindRequest("old", "POST", "app2"),
+			bindRequest("new", "DELETE", "app1"),
+			bindRequest("old", "POST", "app1"),
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/new") && r.Method == "DELETE"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeRename{}
+	command.Flags().Parse(true, []string{"-y"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, "bind failed")
+	c.Assert(stdout.String(), check.Equals, `Creating volume "new"...
+Unbinding volume "old" from app "app1" at "/mnt"...
+Binding volume "new" to app "app1" at "/mnt"...
+Unbinding volume "old" from app "app2" at "/mnt"...
+Binding volume "new" to app "app2" at "/mnt"...
+Failed to bind volume "new", rolling back...
+`)
+	c.Assert(stderr.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeRenameUnbindFailure(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"old", "new"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	volume := `{"Name":"old","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team1","Binds":[{"ID":{"App":"app1","MountPoint":"/mnt","Volume":"old"}}]}`
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: volume, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/old") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "unbind failed", Status: http.StatusInternalServerError},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/old/bind") && r.Method == "DELETE"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/new") && r.Method == "DELETE"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeRename{}
+	command.Flags().Parse(true, []string{"-y"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, "unbind failed")
+	c.Assert(stdout.String(), check.Equals, `Creating volume "new"...
+Unbinding volume "old" from app "app1" at "/mnt"...
+Failed to unbind volume "old", rolling back...
+`)
+	c.Assert(stderr.String(), check.Equals, "")
+}
+
+func (s *S) TestVolumeCloneInfo(c *check.C) {
+	c.Assert((&VolumeClone{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeClone(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "vol1-copy"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	source := `{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team1","Opts":{"capacity":"1Gi","path":"/exports"},"Binds":[{"ID":{"App":"app1","MountPoint":"/mnt","Volume":"vol1"}}]}`
+	cloned := `{"Name":"vol1-copy","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team2","Opts":{"capacity":"2Gi","path":"/exports"}}`
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: source, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					dec := form.NewDecoder(nil)
+					dec.IgnoreCase(true)
+					dec.IgnoreUnknownKeys(true)
+					dec.UseJSONTags(false)
+					var vol volumeTypes.Volume
+					err := dec.DecodeValues(&vol, r.Form)
+					c.Assert(err, check.IsNil)
+					c.Assert(vol, check.DeepEquals, volumeTypes.Volume{
+						Name:      "vol1-copy",
+						Plan:      volumeTypes.VolumePlan{Name: "nfs"},
+						TeamOwner: "team2",
+						Pool:      "pool1",
+						Opts:      map[string]string{"capacity": "2Gi", "path": "/exports"},
+					})
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: cloned, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1-copy") && r.Method == "GET"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeClone{}
+	command.Flags().Parse(true, []string{"-t", "team2", "-o", "capacity=2Gi"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Volume "vol1" successfully cloned to "vol1-copy".
+
+Name: vol1-copy
+Plan: nfs
+Pool: pool1
+Team: team2
+Capacity: 2Gi
+
+Binds:
++-----+------------+------+
+| App | MountPoint | Mode |
++-----+------------+------+
+
+Plan Opts:
++-----+-------+
+| Key | Value |
++-----+-------+
+
+Opts:
++----------+------------+
+| Key      | Value      |
++----------+------------+
+| capacity | "2Gi"      |
++----------+------------+
+| path     | "/exports" |
++----------+------------+
+`)
+}
+
+func (s *S) TestVolumeCloneWithBinds(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "vol1-copy"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	source := `{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team1","Binds":[{"ID":{"App":"app1","MountPoint":"/mnt","Volume":"vol1"},"ReadOnly":true}]}`
+	var bound bool
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: source, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("App"), check.Equals, "app1")
+					c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt")
+					c.Assert(r.FormValue("ReadOnly"), check.Equals, "true")
+					bound = true
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1-copy/bind") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusNoContent},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1-copy") && r.Method == "GET"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeClone{}
+	command.Flags().Parse(true, []string{"--with-binds"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(bound, check.Equals, true)
+}
+
+func (s *S) TestVolumeResizeInfo(c *check.C) {
+	c.Assert((&VolumeResize{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeResize(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "20Gi"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	volume := `{"Name":"vol1","Pool":"pool1","Plan":{"Name":"ebs"},"TeamOwner":"team1","Opts":{"capacity":"10Gi","class":"gp2"}}`
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: volume, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					dec := form.NewDecoder(nil)
+					dec.IgnoreCase(true)
+					dec.IgnoreUnknownKeys(true)
+					dec.UseJSONTags(false)
+					var vol volumeTypes.Volume
+					err := dec.DecodeValues(&vol, r.Form)
+					c.Assert(err, check.IsNil)
+					c.Assert(vol, check.DeepEquals, volumeTypes.Volume{
+						Name:      "vol1",
+						Plan:      volumeTypes.VolumePlan{Name: "ebs"},
+						TeamOwner: "team1",
+						Pool:      "pool1",
+						Opts:      map[string]string{"capacity": "20Gi", "class": "gp2"},
+					})
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "POST"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	err := (&VolumeResize{}).Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Volume "vol1" successfully resized from 10Gi to 20Gi.`+"\n")
+}
+
+func (s *S) TestVolumeResizeInvalidSize(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "twenty gigs"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	err := (&VolumeResize{}).Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, `invalid size "twenty gigs": .*`)
+}
+
+func (s *S) TestVolumeUsageInfo(c *check.C) {
+	c.Assert((&VolumeUsage{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeUsage(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `[
+		{"Name":"vol1","Pool":"pool1","TeamOwner":"team1","Plan":{"Name":"nfs"},"Opts":{"capacity":"1Gi"}},
+		{"Name":"vol2","Pool":"pool1","TeamOwner":"team2","Plan":{"Name":"ebs","Opts":{"capacity":"512Mi"}}},
+		{"Name":"vol3","Pool":"pool2","TeamOwner":"team1","Plan":{"Name":"nfs"},"Opts":{"capacity":"lots"}}
+]`
+	ctx := cmd.Context{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUsage{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `+-------+---------+----------+---------+
+| Pool  | Volumes | Capacity | Unknown |
++-------+---------+----------+---------+
+| pool1 | 2       | 1536Mi   | 0       |
++-------+---------+----------+---------+
+| pool2 | 1       | -        | 1       |
++-------+---------+----------+---------+
+`)
+	stdout.Reset()
+	command = &VolumeUsage{}
+	command.Flags().Parse(true, []string{"--group-by", "team"})
+	err = command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `+-------+---------+----------+---------+
+| Team  | Volumes | Capacity | Unknown |
++-------+---------+----------+---------+
+| team1 | 2       | 1Gi      | 1       |
++-------+---------+----------+---------+
+| team2 | 1       | 512Mi    | 0       |
++-------+---------+----------+---------+
+`)
+}
+
+func (s *S) TestVolumeUsageBytes(c *check.C) {
+	var stdout bytes.Buffer
+	response := `[
+		{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"},"Opts":{"capacity":"10737418240"}},
+		{"Name":"vol2","Pool":"pool1","Plan":{"Name":"nfs"},"Opts":{"capacity":"536870912"}}
+]`
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeUsage{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `+-------+---------+----------+---------+
+| Pool  | Volumes | Capacity | Unknown |
++-------+---------+----------+---------+
+| pool1 | 2       | 10.5 GiB | 0       |
++-------+---------+----------+---------+
+`)
+	stdout.Reset()
+	command = &VolumeUsage{}
+	command.Flags().Parse(true, []string{"--raw"})
+	err = command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Matches, `(?s).*\| pool1 \| 2       \| 11274289152 \| 0       \|.*`)
+}
+
+func (s *S) TestHumanizeCapacity(c *check.C) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"512", "512 B"},
+		{"1024", "1.0 KiB"},
+		{"1572864", "1.5 MiB"},
+		{"10737418240", "10.0 GiB"},
+		{"10Gi", "10Gi"},
+		{"1.5", "1.5"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		c.Check(humanizeCapacity(tt.value), check.Equals, tt.expected, check.Commentf("value %q", tt.value))
+	}
+}
+
+func (s *S) TestVolumeInfoBindSummary(c *check.C) {
+	bind := func(app, mountPoint string) volumeBindData {
+		return volumeBindData{VolumeBind: volumeTypes.VolumeBind{
+			ID: volumeTypes.VolumeBindID{App: app, MountPoint: mountPoint},
+		}}
+	}
+	tests := []struct {
+		binds    []volumeBindData
+		expected string
+	}{
+		{[]volumeBindData{bind("app1", "/a"), bind("app2", "/a")}, "2 app(s), 1 mount point(s)"},
+		{[]volumeBindData{bind("app1", "/a"), bind("app1", "/b")}, "1 app(s), 2 mount point(s)"},
+	}
+	for _, tt := range tests {
+		apps := volumeBoundApps(tt.binds)
+		c.Check(volumeBindSummary(apps, tt.binds), check.Equals, tt.expected)
+	}
+}
+
+func (s *S) TestVolumeUsageInvalidGroup(c *check.C) {
+	command := &VolumeUsage{}
+	command.Flags().Parse(true, []string{"--group-by", "plan"})
+	err := command.Run(&cmd.Context{}, nil)
+	c.Assert(err, check.ErrorMatches, `invalid group "plan", valid options are: pool, team`)
+}
+
+func (s *S) TestVolumeTLSInsecure(c *check.C) {
+	base := &http.Transport{}
+	client := cmd.NewClient(&http.Client{Transport: base}, nil, manager)
+	original := client.HTTPClient
+	tlsFlags := volumeConn{insecure: true}
+	err := tlsFlags.apply(&cmd.Context{}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(client.HTTPClient, check.Not(check.Equals), original)
+	verbose, ok := client.HTTPClient.Transport.(*cmd.VerboseRoundTripper)
+	c.Assert(ok, check.Equals, true)
+	transport, ok := verbose.RoundTripper.(*http.Transport)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(transport.TLSClientConfig.InsecureSkipVerify, check.Equals, true)
+	c.Assert(base.TLSClientConfig == nil || !base.TLSClientConfig.InsecureSkipVerify, check.Equals, true)
+}
+
+func (s *S) TestVolumeTLSInsecureAndCAFile(c *check.C) {
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--insecure", "--ca-file", "/tmp/ca.pem"})
+	err := command.Run(&cmd.Context{}, cmd.NewClient(&http.Client{}, nil, manager))
+	c.Assert(err, check.ErrorMatches, "the --insecure and --ca-file flags are mutually exclusive")
+}
+
+func (s *S) TestVolumeTLSInvalidCAFile(c *check.C) {
+	rfs := &fstest.RecordingFs{FileContent: "not a certificate"}
+	fsystem = rfs
+	defer func() { fsystem = nil }()
+	tlsFlags := volumeConn{caFile: "/tmp/ca.pem"}
+	err := tlsFlags.apply(&cmd.Context{}, cmd.NewClient(&http.Client{}, nil, manager))
+	c.Assert(err, check.ErrorMatches, `no certificates found in CA file "/tmp/ca.pem"`)
+}
+
+func (s *S) TestVolumeProxyFlag(c *check.C) {
+	base := &http.Transport{}
+	client := cmd.NewClient(&http.Client{Transport: base}, nil, manager)
+	conn := volumeConn{proxy: "http://proxy.example.com:3128"}
+	err := conn.apply(&cmd.Context{}, client)
+	c.Assert(err, check.IsNil)
+	verbose, ok := client.HTTPClient.Transport.(*cmd.VerboseRoundTripper)
+	c.Assert(ok, check.Equals, true)
+	transport, ok := verbose.RoundTripper.(*http.Transport)
+	c.Assert(ok, check.Equals, true)
+	req, err := http.NewRequest("GET", "https://tsuru.example.com/1.4/volumes", nil)
+	c.Assert(err, check.IsNil)
+	proxyURL, err := transport.Proxy(req)
+	c.Assert(err, check.IsNil)
+	c.Assert(proxyURL.String(), check.Equals, "http://proxy.example.com:3128")
+	c.Assert(base.Proxy, check.IsNil)
+}
+
+func (s *S) TestVolumeProxyFromEnvironment(c *check.C) {
+	client := cmd.NewClient(&http.Client{Transport: &http.Transport{}}, nil, manager)
+	err := (&volumeConn{}).apply(&cmd.Context{}, client)
+	c.Assert(err, check.IsNil)
+	transport := client.HTTPClient.Transport.(*cmd.VerboseRoundTripper).RoundTripper.(*http.Transport)
+	c.Assert(transport.Proxy, check.NotNil)
+}
+
+func (s *S) TestVolumeInvalidProxy(c *check.C) {
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--proxy", "proxy.example.com:3128"})
+	err := command.Run(&cmd.Context{}, cmd.NewClient(&http.Client{}, nil, manager))
+	c.Assert(err, check.ErrorMatches, `invalid proxy "proxy.example.com:3128", it must be a URL like http://proxy.example.com:3128`)
+}
+
+func (s *S) TestVolumeDebugFlag(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+		CondFunc: func(r *http.Request) bool {
+			c.Assert(r.Header.Get("Authorization"), check.Equals, "bearer sometoken")
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"-o", "a=1", "--debug"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully created.\n")
+	debug := stderr.String()
+	c.Assert(strings.HasPrefix(debug, "DEBUG: POST http://localhost:8080/1.4/volumes\n"), check.Equals, true, check.Commentf("Got: %s", debug))
+	c.Assert(debug, check.Matches, `(?s).*DEBUG:   Authorization: <redacted>\n.*`)
+	c.Assert(debug, check.Matches, `(?s).*DEBUG:   Body: .*Opts\.a=1.*`)
+	c.Assert(strings.Contains(debug, "sometoken"), check.Equals, false)
+}
+
+func (s *S) TestVolumeAPIVersionFlag(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "plan1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: "", Status: http.StatusCreated},
+		CondFunc: func(r *http.Request) bool {
+			return r.URL.Path == "/1.5/volumes" && r.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreate{}
+	command.Flags().Parse(true, []string{"--api-version", "1.5"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volume successfully created.\n")
+	u, err := (&volumeConn{}).volumeURL("/volumes")
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.HasSuffix(u, "/1.4/volumes"), check.Equals, true, check.Commentf("Got: %s", u))
+}
+
+func (s *S) TestVolumeInvalidAPIVersionFlag(c *check.C) {
+	command := &VolumeList{}
+	command.Flags().Parse(true, []string{"--api-version", "v2"})
+	err := command.Run(&cmd.Context{}, nil)
+	c.Assert(err, check.ErrorMatches, `invalid API version "v2", it must be like 1.4`)
+}
+
+func (s *S) TestVolumeExportInfo(c *check.C) {
+	c.Assert((&VolumeExport{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeExportYAML(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	response := `[
+		{"Name":"vol2","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin","Status":"ready","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt","Volume":"vol2"}}],"Opts":{"capacity":"1Gi"}},
+		{"Name":"vol1","Pool":"pool1","Plan":{"Name":"ebs"},"TeamOwner":"admin"},
+		{"Name":"vol3","Pool":"pool2","Plan":{"Name":"nfs"},"TeamOwner":"admin"}
+]`
+	ctx := cmd.Context{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeExport{}
+	command.Flags().Parse(true, []string{"--pool", "pool1"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `- Binds: null
+  Name: vol1
+  Opts: null
+  Plan:
+    Name: ebs
+    Opts: null
+  Pool: pool1
+  Status: ""
+  TeamOwner: admin
+- Binds: null
+  Name: vol2
+  Opts:
+    capacity: 1Gi
+  Plan:
+    Name: nfs
+    Opts: null
+  Pool: pool1
+  Status: ""
+  TeamOwner: admin
+`)
+}
+
+func (s *S) TestVolumeExportJSONToFileWithBinds(c *check.C) {
+	rfs := &fstest.RecordingFs{}
+	fsystem = rfs
+	defer func() { fsystem = nil }()
+	var stdout, stderr bytes.Buffer
+	response := `[{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"admin","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt","Volume":"vol1"},"ReadOnly":true}]}]`
+	ctx := cmd.Context{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: response, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return strings.HasSuffix(req.URL.Path, "/volumes") && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeExport{}
+	command.Flags().Parse(true, []string{"--json", "--with-binds", "--file", "/tmp/volumes.json"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Exported 1 volume(s) to /tmp/volumes.json.\n")
+	f, err := rfs.Open("/tmp/volumes.json")
+	c.Assert(err, check.IsNil)
+	var volumes []volumeTypes.Volume
+	err = json.NewDecoder(f).Decode(&volumes)
+	c.Assert(err, check.IsNil)
+	c.Assert(volumes, check.DeepEquals, []volumeTypes.Volume{{
+		Name:      "vol1",
+		Pool:      "pool1",
+		Plan:      volumeTypes.VolumePlan{Name: "nfs"},
+		TeamOwner: "admin",
+		Binds: []volumeTypes.VolumeBind{
+			{ID: volumeTypes.VolumeBindID{App: "myapp", MountPoint: "/mnt", Volume: "vol1"}, ReadOnly: true},
+		},
+	}})
+}
+
+func (s *S) TestVolumeImportInfo(c *check.C) {
+	c.Assert((&VolumeImport{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeImport(c *check.C) {
+	rfs := &fstest.RecordingFs{FileContent: `
+- Name: vol1
+  Pool: pool1
+  TeamOwner: admin
+  Plan:
+    Name: nfs
+  Opts:
+    capacity: 1Gi
+  Binds:
+  - ID:
+      App: myapp
+      MountPoint: /mnt
+    ReadOnly: true
+- Name: vol2
+  Pool: pool1
+  TeamOwner: admin
+  Plan:
+    Name: nfs
+- Name: vol3
+  Pool: pool1
+  TeamOwner: admin
+  Plan:
+    Name: unknown
+`}
+	fsystem = rfs
+	defer func() { fsystem = nil }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"volumes.yaml"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: `[{"Name":"vol2"}]`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("Name"), check.Equals, "vol1")
+					c.Assert(r.FormValue("Plan.Name"), check.Equals, "nfs")
+					c.Assert(r.FormValue("Opts.capacity"), check.Equals, "1Gi")
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("App"), check.Equals, "myapp")
+					c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt")
+					c.Assert(r.FormValue("ReadOnly"), check.Equals, "true")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "plan not found", Status: http.StatusBadRequest},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("Name"), check.Equals, "vol3")
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeImport{}
+	command.Flags().Parse(true, []string{"--with-binds"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, "failed to import 1 of 3 volumes")
+	c.Assert(stdout.String(), check.Equals, `Creating volume "vol1"...
+Binding volume "vol1" to app "myapp" at "/mnt"...
+Creating volume "vol3"...
+Summary:
+  vol1: created, 1 bind(s) created
+  vol2: skipped (already exists)
+  vol3: failed (plan not found)
+`)
+	c.Assert(stderr.String(), check.Equals, "Failed to import volume \"vol3\": plan not found\n")
+}
+
+func (s *S) TestVolumeImportUpdate(c *check.C) {
+	fsystem = &fstest.RecordingFs{FileContent: `[{"Name":"vol1","Pool":"pool2","TeamOwner":"admin","Plan":{"Name":"nfs"}}]`}
+	defer func() { fsystem = nil }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"volumes.json"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: `[{"Name":"vol1","Pool":"pool1"}]`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("Pool"), check.Equals, "pool2")
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "POST"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeImport{}
+	command.Flags().Parse(true, []string{"--update"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Updating volume "vol1"...
+Summary:
+  vol1: updated
+`)
+}
+
+func (s *S) TestVolumeMoveInfo(c *check.C) {
+	c.Assert((&VolumeMove{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeMove(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "pool2"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Stdin:  strings.NewReader("y\n"),
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: `{"Name":"vol1","Pool":"pool1","TeamOwner":"admin","Plan":{"Name":"nfs"},"Opts":{"capacity":"1Gi"}}`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					dec := form.NewDecoder(nil)
+					dec.IgnoreCase(true)
+					dec.IgnoreUnknownKeys(true)
+					dec.UseJSONTags(false)
+					var vol volumeTypes.Volume
+					err := dec.DecodeValues(&vol, r.Form)
+					c.Assert(err, check.IsNil)
+					c.Assert(vol, check.DeepEquals, volumeTypes.Volume{
+						Name:      "vol1",
+						Plan:      volumeTypes.VolumePlan{Name: "nfs"},
+						Pool:      "pool2",
+						TeamOwner: "admin",
+						Opts:      map[string]string{"capacity": "1Gi"},
+					})
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "POST"
+				},
+			},
 		},
 	}
 	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
-	command := &VolumeBind{}
-	command.Flags().Parse(true, []string{"-a", "myapp", "--readonly"})
+	command := &VolumeMove{}
+	command.Flags().Parse(true, []string{})
 	err := command.Run(&ctx, client)
 	c.Assert(err, check.IsNil)
-	result := stdout.String()
-	c.Assert(result, check.Equals, "Volume successfully bound.\n")
+	c.Assert(stdout.String(), check.Equals, `Are you sure you want to move volume "vol1" from pool "pool1" to "pool2"? (y/n) Volume "vol1" successfully moved from pool "pool1" to "pool2".`+"\n")
 }
 
-func (s *S) TestVolumeUnbindInfo(c *check.C) {
-	c.Assert((&VolumeUnbind{}).Info(), check.NotNil)
+func (s *S) TestVolumeMoveRejected(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "pool2"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: `{"Name":"vol1","Pool":"pool1","Plan":{"Name":"nfs"}}`, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "updating a volume already provisioned is not supported", Status: http.StatusBadRequest},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "POST"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeMove{}
+	command.Flags().Parse(true, []string{"-y"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, `(?s)unable to move volume "vol1" to pool "pool2": updating a volume already provisioned is not supported\nIf the backend can't move volume data, use volume-clone .*`)
 }
 
-func (s *S) TestVolumeUnbind(c *check.C) {
+func (s *S) TestVolumeDiffInfo(c *check.C) {
+	c.Assert((&VolumeDiff{}).Info(), check.NotNil)
+}
+
+func volumeDiffTransport(a, b string) http.RoundTripper {
+	return &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: a, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: b, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					return strings.HasSuffix(r.URL.Path, "/volumes/vol2") && r.Method == "GET"
+				},
+			},
+		},
+	}
+}
+
+func (s *S) TestVolumeDiff(c *check.C) {
 	var stdout, stderr bytes.Buffer
 	ctx := cmd.Context{
-		Args:   []string{"vol1", "/mnt"},
+		Args:   []string{"vol1", "vol2"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := volumeDiffTransport(
+		`{"Name":"vol1","Pool":"pool1","TeamOwner":"admin","Plan":{"Name":"nfs","Opts":{"plugin":"nfs","replicas":2}},"Opts":{"capacity":"1Gi","path":"/exports"}}`,
+		`{"Name":"vol2","Pool":"pool2","TeamOwner":"admin","Plan":{"Name":"nfs","Opts":{"plugin":"nfs","replicas":3}},"Opts":{"capacity":"1Gi","server":"10.0.0.1"}}`,
+	)
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeDiff{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `--- vol1
++++ vol2
+@@ Attributes @@
+-pool: "pool1"
++pool: "pool2"
+@@ Plan Opts @@
+-replicas: 2
++replicas: 3
+@@ Opts @@
+-path: "/exports"
++server: "10.0.0.1"
+`)
+}
+
+func (s *S) TestRenderVolumeDiffSectionColors(c *check.C) {
+	var buf bytes.Buffer
+	renderVolumeDiffSection(&buf, "Opts", map[string]volumeDiffValue{"capacity": {A: "1Gi", B: "2Gi"}}, true)
+	c.Assert(buf.String(), check.Equals, "@@ Opts @@\n"+
+		cmd.Colorfy(`-capacity: "1Gi"`, "red", "", "")+"\n"+
+		cmd.Colorfy(`+capacity: "2Gi"`, "green", "", "")+"\n")
+}
+
+func (s *S) TestVolumeDiffIdentical(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "vol2"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := volumeDiffTransport(
+		`{"Name":"vol1","Pool":"pool1","TeamOwner":"admin","Plan":{"Name":"nfs"},"Opts":{"capacity":"1Gi"}}`,
+		`{"Name":"vol2","Pool":"pool1","TeamOwner":"admin","Plan":{"Name":"nfs"},"Opts":{"capacity":"1Gi"}}`,
+	)
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeDiff{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "Volumes \"vol1\" and \"vol2\" are identical.\n")
+}
+
+func (s *S) TestVolumeDiffJSON(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "vol2"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := volumeDiffTransport(
+		`{"Name":"vol1","Pool":"pool1","TeamOwner":"admin","Plan":{"Name":"nfs"},"Opts":{"capacity":"1Gi"}}`,
+		`{"Name":"vol2","Pool":"pool1","TeamOwner":"team2","Plan":{"Name":"nfs"},"Opts":{"capacity":"2Gi"}}`,
+	)
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeDiff{}
+	command.Flags().Parse(true, []string{"--json"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	var diff map[string]interface{}
+	err = json.Unmarshal(stdout.Bytes(), &diff)
+	c.Assert(err, check.IsNil)
+	c.Assert(diff, check.DeepEquals, map[string]interface{}{
+		"a":          "vol1",
+		"b":          "vol2",
+		"attributes": map[string]interface{}{"team": map[string]interface{}{"a": "admin", "b": "team2"}},
+		"planOpts":   map[string]interface{}{},
+		"opts":       map[string]interface{}{"capacity": map[string]interface{}{"a": "1Gi", "b": "2Gi"}},
+	})
+}
+
+func (s *S) TestVolumeDiffNotFound(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1", "vol2"},
 		Stdout: &stdout,
 		Stderr: &stderr,
 	}
 	trans := &cmdtest.ConditionalTransport{
-		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		Transport: cmdtest.Transport{Status: http.StatusNoContent},
 		CondFunc: func(r *http.Request) bool {
-			r.ParseForm()
-			c.Assert(r.FormValue("App"), check.Equals, "myapp")
-			c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt")
-			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+			return strings.HasSuffix(r.URL.Path, "/volumes/vol1") && r.Method == "GET"
 		},
 	}
 	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
-	command := &VolumeUnbind{}
-	command.Flags().Parse(true, []string{"-a", "myapp"})
+	command := &VolumeDiff{}
+	command.Flags().Parse(true, []string{})
 	err := command.Run(&ctx, client)
-	c.Assert(err, check.IsNil)
-	result := stdout.String()
-	c.Assert(result, check.Equals, "Volume successfully unbound.\n")
+	c.Assert(err, check.ErrorMatches, `volume "vol1" not found`)
 }
 
-func (s *S) TestVolumeUnbindNoRestart(c *check.C) {
+func (s *S) TestVolumeCreateBatchInfo(c *check.C) {
+	c.Assert((&VolumeCreateBatch{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeCreateBatch(c *check.C) {
+	fsystem = &fstest.RecordingFs{FileContent: `name,plan,pool,team,capacity,path
+vol1,nfs,pool1,,1Gi,
+vol2,,pool1,admin,,
+vol3,ebs,pool1,admin,,/exports
+`}
+	defer func() { fsystem = nil }()
+	os.Setenv("TSURU_TEAM", "team1")
+	defer os.Unsetenv("TSURU_TEAM")
 	var stdout, stderr bytes.Buffer
 	ctx := cmd.Context{
-		Args:   []string{"vol1", "/mnt"},
+		Args:   []string{"volumes.csv"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Status: http.StatusCreated},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("Name"), check.Equals, "vol1")
+					c.Assert(r.FormValue("Plan.Name"), check.Equals, "nfs")
+					c.Assert(r.FormValue("Pool"), check.Equals, "pool1")
+					c.Assert(r.FormValue("TeamOwner"), check.Equals, "team1")
+					c.Assert(r.FormValue("Opts.capacity"), check.Equals, "1Gi")
+					c.Assert(r.Form["Opts.path"], check.IsNil)
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+			{
+				Transport: cmdtest.Transport{Message: "plan not found", Status: http.StatusBadRequest},
+				CondFunc: func(r *http.Request) bool {
+					r.ParseForm()
+					c.Assert(r.FormValue("Name"), check.Equals, "vol3")
+					c.Assert(r.FormValue("Opts.path"), check.Equals, "/exports")
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
+				},
+			},
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeCreateBatch{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.ErrorMatches, "failed to create 2 of 3 volumes")
+	c.Assert(stdout.String(), check.Equals, `Creating volume "vol1"...
+Creating volume "vol3"...
+Summary:
+  vol1: created
+  vol2: failed (volume plan is required)
+  vol3: failed (plan not found)
+`)
+	c.Assert(stderr.String(), check.Equals, `Failed to create volume from line 3: volume plan is required
+Failed to create volume from line 4: plan not found
+`)
+}
+
+func (s *S) TestVolumeCreateBatchWithoutHeader(c *check.C) {
+	fsystem = &fstest.RecordingFs{FileContent: "vol1,nfs,pool1,admin,capacity=1Gi\n"}
+	defer func() { fsystem = nil }()
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"volumes.csv"},
 		Stdout: &stdout,
 		Stderr: &stderr,
 	}
 	trans := &cmdtest.ConditionalTransport{
-		Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+		Transport: cmdtest.Transport{Status: http.StatusCreated},
 		CondFunc: func(r *http.Request) bool {
 			r.ParseForm()
-			c.Assert(r.FormValue("App"), check.Equals, "myapp")
-			c.Assert(r.FormValue("MountPoint"), check.Equals, "/mnt")
-			c.Assert(r.FormValue("NoRestart"), check.Equals, "true")
-			return strings.HasSuffix(r.URL.Path, "/volumes/vol1/bind") && r.Method == "DELETE"
+			c.Assert(r.FormValue("Name"), check.Equals, "vol1")
+			c.Assert(r.FormValue("TeamOwner"), check.Equals, "admin")
+			c.Assert(r.FormValue("Opts.capacity"), check.Equals, "1Gi")
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "POST"
 		},
 	}
 	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
-	command := &VolumeUnbind{}
-	command.Flags().Parse(true, []string{"-a", "myapp", "--no-restart"})
+	command := &VolumeCreateBatch{}
+	command.Flags().Parse(true, []string{})
 	err := command.Run(&ctx, client)
 	c.Assert(err, check.IsNil)
-	result := stdout.String()
-	c.Assert(result, check.Equals, "Volume successfully unbound.\n")
+	c.Assert(stdout.String(), check.Equals, "Creating volume \"vol1\"...\nSummary:\n  vol1: created\n")
 }
 
-func (s *S) TestVolumeClientSideFilter(c *check.C) {
-	volumes := []volumeTypes.Volume{
+func (s *S) TestVolumePlanShowInfo(c *check.C) {
+	c.Assert((&VolumePlanShow{}).Info(), check.NotNil)
+}
 
-		{
-			Name:      "gcp-volume-01",
-			Pool:      "gcp-pool-01",
-			Plan:      volumeTypes.VolumePlan{Name: "big"},
-			TeamOwner: "their-team",
-		},
-		{
-			Name:      "gcp-volume-02",
-			Pool:      "gcp-pool-02",
-			Plan:      volumeTypes.VolumePlan{Name: "small"},
-			TeamOwner: "my-team",
+func volumePlanShowClient() *cmd.Client {
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{
+			Message: `{"kubernetes":[{"Name":"nfs","Opts":{"access-modes":"ReadWriteMany","replicas":2}},{"Name":"ebs"}],"swarm":[{"Name":"local"}]}`,
+			Status:  http.StatusOK,
 		},
-		{
-			Name:      "aws-volume-01",
-			Pool:      "aws-pool-01",
-			Plan:      volumeTypes.VolumePlan{Name: "small"},
-			TeamOwner: "their-team",
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumeplans") && r.Method == "GET"
 		},
 	}
+	return cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+}
 
-	filters := []volumeFilter{
-		{
-			name: "gcp",
-		},
-		{
-			name: "aws",
-		},
-		{
-			pool: "aws-pool-01",
-		},
+func (s *S) TestVolumePlanShow(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"nfs"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	command := &VolumePlanShow{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&ctx, volumePlanShowClient())
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `Name: nfs
+Provisioner: kubernetes
 
-		{
-			name: "gcp",
-			pool: "gcp-pool-02",
-		},
+Opts:
++--------------+-----------------+
+| Key          | Value           |
++--------------+-----------------+
+| access-modes | "ReadWriteMany" |
++--------------+-----------------+
+| replicas     | 2               |
++--------------+-----------------+
+`)
+}
 
-		{
-			plan: "small",
+func (s *S) TestVolumePlanShowJSON(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"ebs"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	command := &VolumePlanShow{}
+	command.Flags().Parse(true, []string{"--json"})
+	err := command.Run(&ctx, volumePlanShowClient())
+	c.Assert(err, check.IsNil)
+	var details []volumePlanDetail
+	err = json.Unmarshal(stdout.Bytes(), &details)
+	c.Assert(err, check.IsNil)
+	c.Assert(details, check.DeepEquals, []volumePlanDetail{{Name: "ebs", Provisioner: "kubernetes"}})
+}
+
+func (s *S) TestVolumePlanShowNotFound(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"gluster"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	command := &VolumePlanShow{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&ctx, volumePlanShowClient())
+	c.Assert(err, check.ErrorMatches, `volume plan "gluster" not found, available plans are: ebs, local, nfs`)
+}
+
+const volumeEventsData = `[
+  {
+    "StartTime": "2016-07-19T11:28:24-03:00",
+    "EndTime": "2016-07-19T11:28:26-03:00",
+    "Target": {"Type": "volume", "Value": "vol1"},
+    "Kind": {"Type": "permission", "Name": "volume.create"},
+    "Owner": {"Type": "user", "Name": "admin@example.com"},
+    "Error": "",
+    "Running": false
+  },
+  {
+    "StartTime": "2016-07-19T12:00:00-03:00",
+    "EndTime": "2016-07-19T12:01:05-03:00",
+    "Target": {"Type": "volume", "Value": "vol1"},
+    "Kind": {"Type": "permission", "Name": "volume.bind"},
+    "Owner": {"Type": "user", "Name": "admin@example.com"},
+    "Error": "mount failed",
+    "Running": false
+  }
+]`
+
+func (s *S) TestVolumeEventsInfo(c *check.C) {
+	c.Assert((&VolumeEvents{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumeEvents(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: volumeEventsData, Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			c.Assert(r.URL.Query().Get("target.type"), check.Equals, "volume")
+			c.Assert(r.URL.Query().Get("target.value"), check.Equals, "vol1")
+			c.Assert(r.URL.Query().Get("limit"), check.Equals, "2")
+			return r.URL.Path == "/1.1/events" && r.Method == "GET"
 		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeEvents{}
+	command.Flags().Parse(true, []string{"--limit", "2"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `+-----------------------------+---------------+-------------------+---------+
+| Start (duration)            | Kind          | Owner             | Success |
++-----------------------------+---------------+-------------------+---------+
+| 19 Jul 16 09:28 CDT (00:02) | volume.create | admin@example.com | true    |
+| 19 Jul 16 10:00 CDT (01:05) | volume.bind   | admin@example.com | false   |
++-----------------------------+---------------+-------------------+---------+
+`)
+}
 
-		{
-			teamOwner: "my-team",
+func (s *S) TestVolumeEventsJSON(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: volumeEventsData, Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			c.Assert(r.URL.Query().Get("limit"), check.Equals, "")
+			return r.URL.Path == "/1.1/events" && r.Method == "GET"
 		},
 	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeEvents{}
+	command.Flags().Parse(true, []string{"--json"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	var evts []map[string]interface{}
+	err = json.Unmarshal(stdout.Bytes(), &evts)
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 2)
+	c.Assert(evts[1]["Error"], check.Equals, "mount failed")
+}
 
-	expectedResults := [][]string{
-		{"gcp-volume-01", "gcp-volume-02"},
-		{"aws-volume-01"},
-		{"aws-volume-01"},
-		{"gcp-volume-02"},
-		{"gcp-volume-02", "aws-volume-01"},
-		{"gcp-volume-02"},
+func (s *S) TestVolumeEventsNoEvents(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{
+		Args:   []string{"vol1"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Status: http.StatusNoContent},
+		CondFunc: func(r *http.Request) bool {
+			return r.URL.Path == "/1.1/events" && r.Method == "GET"
+		},
 	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumeEvents{}
+	command.Flags().Parse(true, []string{})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "No events found for volume \"vol1\".\n")
+}
 
-	for i := range filters {
-		cl := VolumeList{
-			filter: filters[i],
-		}
+func (s *S) TestVolumeEventsInvalidLimit(c *check.C) {
+	var stdout bytes.Buffer
+	ctx := cmd.Context{Args: []string{"vol1"}, Stdout: &stdout}
+	command := &VolumeEvents{}
+	command.Flags().Parse(true, []string{"--limit", "-1"})
+	err := command.Run(&ctx, nil)
+	c.Assert(err, check.ErrorMatches, "invalid limit -1, it must be a positive number")
+}
 
-		filteredVolumes := cl.clientSideFilter(volumes)
-		result := []string{}
-		for _, volume := range filteredVolumes {
-			result = append(result, volume.Name)
-		}
+const volumePruneList = `[
+	{"Name":"c-vol","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team1","Opts":{"capacity":"512Mi"}},
+	{"Name":"b-vol","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team1","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt","Volume":"b-vol"}}]},
+	{"Name":"a-vol","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team1","Opts":{"capacity":"1Gi"}},
+	{"Name":"d-vol","Pool":"pool1","Plan":{"Name":"nfs"},"TeamOwner":"team1"}
+]`
 
-		if !c.Check(result, check.DeepEquals, expectedResults[i]) {
-			c.Errorf("Failed to test case: %d, %#v", i, filters[i])
-		}
+func (s *S) TestVolumePruneInfo(c *check.C) {
+	c.Assert((&VolumePrune{}).Info(), check.NotNil)
+}
+
+func (s *S) TestVolumePrune(c *check.C) {
+	var stdout, stderr bytes.Buffer
+	ctx := cmd.Context{Stdout: &stdout, Stderr: &stderr}
+	var deleted []string
+	trans := &cmdtest.MultiConditionalTransport{
+		ConditionalTransports: []cmdtest.ConditionalTransport{
+			{
+				Transport: cmdtest.Transport{Message: volumePruneList, Status: http.StatusOK},
+				CondFunc: func(r *http.Request) bool {
+					c.Assert(r.URL.Query().Get("pool"), check.Equals, "pool1")
+					return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "GET"
+				},
+			},
+		},
+	}
+	for i := 0; i < 3; i++ {
+		trans.ConditionalTransports = append(trans.ConditionalTransports, cmdtest.ConditionalTransport{
+			Transport: cmdtest.Transport{Message: "", Status: http.StatusOK},
+			CondFunc: func(r *http.Request) bool {
+				deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/1.4/volumes/"))
+				return r.Method == "DELETE"
+			},
+		})
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumePrune{}
+	command.Flags().Parse(true, []string{"-o", "pool1", "-y"})
+	err := command.Run(&ctx, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(deleted, check.DeepEquals, []string{"a-vol", "c-vol", "d-vol"})
+	c.Assert(stdout.String(), check.Equals, `The following unbound volumes will be deleted:
+ - a-vol (1Gi)
+ - c-vol (512Mi)
+ - d-vol
+Deleted 3 volume(s), reclaiming 1536Mi.
+`)
+}
+
+func (s *S) TestVolumePruneDryRun(c *check.C) {
+	var stdout bytes.Buffer
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{Message: volumePruneList, Status: http.StatusOK},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumePrune{}
+	command.Flags().Parse(true, []string{"--dry-run"})
+	err := command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, `The following unbound volumes would be deleted:
+ - a-vol (1Gi)
+ - c-vol (512Mi)
+ - d-vol
+`)
+}
+
+func (s *S) TestVolumePruneNothingToDelete(c *check.C) {
+	var stdout bytes.Buffer
+	trans := &cmdtest.ConditionalTransport{
+		Transport: cmdtest.Transport{
+			Message: `[{"Name":"b-vol","Binds":[{"ID":{"App":"myapp","MountPoint":"/mnt","Volume":"b-vol"}}]}]`,
+			Status:  http.StatusOK,
+		},
+		CondFunc: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/volumes") && r.Method == "GET"
+		},
 	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := &VolumePrune{}
+	command.Flags().Parse(true, []string{"-y"})
+	err := command.Run(&cmd.Context{Stdout: &stdout}, client)
+	c.Assert(err, check.IsNil)
+	c.Assert(stdout.String(), check.Equals, "No unbound volumes to delete.\n")
 }
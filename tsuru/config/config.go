@@ -48,6 +48,7 @@ type ConfigType struct {
 
 	// ---- public confs ----
 	ClientSelfUpdater ClientSelfUpdater
+	Volume            *VolumeConfig `json:",omitempty"`
 }
 
 func newDefaultConf() *ConfigType {
@@ -170,3 +171,11 @@ type ClientSelfUpdater struct {
 	LatestManifestURL string
 	LastCheck         time.Time
 }
+
+// VolumeConfig saves the defaults used by the volume commands
+type VolumeConfig struct {
+	Output          string `json:",omitempty"` // default output format: table, json or yaml
+	StorageClassOpt string `json:",omitempty"` // opt key set by volume-create --storage-class
+	NamespaceOpt    string `json:",omitempty"` // opt key set by volume-create --namespace
+	NoRestart       bool   `json:",omitempty"` // default of --no-restart in volume-bind and volume-unbind
+}
@@ -61,6 +61,21 @@ func (s *S) TestBoostrapConfigFromFile(c *check.C) {
 	c.Assert(conf, check.DeepEquals, expected)
 }
 
+func (s *S) TestBoostrapConfigVolumeOutput(c *check.C) {
+	fsystem = &fstest.RecordingFs{}
+	f, err := fsystem.OpenFile(configPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	c.Assert(err, check.IsNil)
+	fmt.Fprintf(f, `{
+  "SchemaVersion": "6.6.6",
+  "volume": {"output": "json"}
+}`)
+	f.Close()
+
+	conf := bootstrapConfig()
+	c.Assert(conf, check.NotNil)
+	c.Assert(conf.Volume, check.DeepEquals, &VolumeConfig{Output: "json"})
+}
+
 func (s *S) TestBoostrapConfigWrongFormatBackupFile(c *check.C) {
 	stdout = &bytes.Buffer{}
 	stderr = &bytes.Buffer{}
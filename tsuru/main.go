@@ -5,9 +5,13 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/ajg/form"
+	"github.com/tsuru/gnuflag"
 	"github.com/tsuru/tsuru-client/tsuru/admin"
 	"github.com/tsuru/tsuru-client/tsuru/client"
 	"github.com/tsuru/tsuru-client/tsuru/config"
@@ -164,14 +168,28 @@ Services aren’t managed by tsuru, but by their creators.`)
 	m.Register(&admin.ClusterList{})
 
 	m.RegisterTopic("volume", "Volumes allow applications running on tsuru to use external storage volumes mounted on their filesystem.")
-	m.Register(&client.VolumeCreate{})
-	m.Register(&client.VolumeUpdate{})
-	m.Register(&client.VolumeList{})
-	m.Register(&client.VolumePlansList{})
-	m.Register(&client.VolumeDelete{})
-	m.Register(&client.VolumeInfo{})
-	m.Register(&client.VolumeBind{})
-	m.Register(&client.VolumeUnbind{})
+	m.Register(&exitCodeCommand{&client.VolumeCreate{}})
+	m.Register(&exitCodeCommand{&client.VolumeUpdate{}})
+	m.Register(&exitCodeCommand{&client.VolumeList{}})
+	m.Register(&exitCodeCommand{&client.VolumePlansList{}})
+	m.Register(&exitCodeCommand{&client.VolumePlanShow{}})
+	m.Register(&exitCodeCommand{&client.VolumeDelete{}})
+	m.Register(&exitCodeCommand{&client.VolumeInfo{}})
+	m.Register(&exitCodeCommand{&client.VolumeBind{}})
+	m.Register(&exitCodeCommand{&client.VolumeUnbind{}})
+	m.Register(&exitCodeCommand{&client.VolumeBindList{}})
+	m.Register(&exitCodeCommand{&client.VolumeRename{}})
+	m.Register(&exitCodeCommand{&client.VolumeClone{}})
+	m.Register(&exitCodeCommand{&client.VolumeResize{}})
+	m.Register(&exitCodeCommand{&client.VolumeMove{}})
+	m.Register(&exitCodeCommand{&client.VolumeDiff{}})
+	m.Register(&exitCodeCommand{&client.VolumeUsage{}})
+	m.Register(&exitCodeCommand{&client.VolumeExport{}})
+	m.Register(&exitCodeCommand{&client.VolumeImport{}})
+	m.Register(&exitCodeCommand{&client.VolumeCreateBatch{}})
+	m.Register(&exitCodeCommand{&client.VolumeComplete{}})
+	m.Register(&exitCodeCommand{&client.VolumeEvents{}})
+	m.Register(&exitCodeCommand{&client.VolumePrune{}})
 	m.Register(&client.AppRoutersList{})
 	m.Register(&client.AppRoutersAdd{})
 	m.Register(&client.AppRoutersRemove{})
@@ -210,6 +228,37 @@ func registerExtraCommands(m *cmd.Manager) {
 	}
 }
 
+// exitCodeCommand makes the process exit with the code carried by the errors
+// of the wrapped command, such as the volume commands, as the manager exits
+// with status 1 on any error.
+type exitCodeCommand struct {
+	cmd.Command
+}
+
+func (c *exitCodeCommand) Flags() *gnuflag.FlagSet {
+	if flagged, ok := c.Command.(cmd.FlaggedCommand); ok {
+		return flagged.Flags()
+	}
+	return gnuflag.NewFlagSet(c.Info().Name, gnuflag.ExitOnError)
+}
+
+func (c *exitCodeCommand) Run(context *cmd.Context, client *cmd.Client) error {
+	err := c.Command.Run(context, client)
+	var exitErr interface{ ExitCode() int }
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	if !errors.Is(err, cmd.ErrAbortCommand) {
+		errorMsg := err.Error()
+		var bodyErr interface{ Body() []byte }
+		if errors.As(err, &bodyErr) && len(bodyErr.Body()) > 0 {
+			errorMsg = fmt.Sprintf("%s: %s", errorMsg, bodyErr.Body())
+		}
+		fmt.Fprintf(context.Stderr, "Error: %s\n", strings.TrimSuffix(errorMsg, "\n"))
+	}
+	panic(&cmd.PanicExitError{Code: exitErr.ExitCode()})
+}
+
 func recoverCmdPanicExitError() {
 	if r := recover(); r != nil {
 		if e, ok := r.(*cmd.PanicExitError); ok {
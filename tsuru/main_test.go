@@ -6,6 +6,7 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -450,3 +451,34 @@ func (s *S) TestServiceTemplateIsRegistered(c *check.C) {
 	c.Assert(ok, check.Equals, true)
 	c.Assert(list, check.FitsTypeOf, &admin.ServiceTemplate{})
 }
+
+type exitCodeErr struct{}
+
+func (exitCodeErr) Error() string { return "not found" }
+
+func (exitCodeErr) ExitCode() int { return 3 }
+
+type failingCommand struct{ err error }
+
+func (c *failingCommand) Info() *cmd.Info { return &cmd.Info{Name: "failing"} }
+
+func (c *failingCommand) Run(*cmd.Context, *cmd.Client) error { return c.err }
+
+func (s *S) TestExitCodeCommand(c *check.C) {
+	var stderr bytes.Buffer
+	command := &exitCodeCommand{&failingCommand{err: exitCodeErr{}}}
+	c.Assert(command.Flags(), check.NotNil)
+	defer func() {
+		r := recover()
+		c.Assert(r, check.DeepEquals, &cmd.PanicExitError{Code: 3})
+		c.Assert(stderr.String(), check.Equals, "Error: not found\n")
+	}()
+	command.Run(&cmd.Context{Stderr: &stderr}, nil)
+	c.Fatal("expected the command to exit")
+}
+
+func (s *S) TestExitCodeCommandWithoutCode(c *check.C) {
+	command := &exitCodeCommand{&failingCommand{err: errors.New("failed")}}
+	err := command.Run(&cmd.Context{}, nil)
+	c.Assert(err, check.ErrorMatches, "failed")
+}